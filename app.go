@@ -2,34 +2,59 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"macbox/internal/export"
 	"macbox/internal/services"
 	"macbox/internal/tools"
+	"net/netip"
+	"os"
+	"slices"
+	"time"
 
+	"macbox/pkg/dhcp"
+	"macbox/pkg/mavlink"
+	"macbox/pkg/mdns"
 	"macbox/pkg/network"
+	"macbox/pkg/scan"
+	"macbox/pkg/settings"
 	"macbox/pkg/watcher"
+	"macbox/pkg/wifi"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx            context.Context
-	version        string
-	networkService *services.NetworkService
-	updateService  *services.UpdateService
-	watcherService *services.WatcherService
+	ctx             context.Context
+	version         string
+	networkService  *services.NetworkService
+	updateService   *services.UpdateService
+	watcherService  *services.WatcherService
+	settingsService *services.SettingsService
+	wifiService     *services.WifiService
+	dhcpServer      *services.DHCPServerService
+	mavlinkScan     *services.MAVLinkDiscoveryService
+	mdnsService     *services.MDNSService
 
 	pingTool *tools.PingTool
+	scanTool *tools.ScanTool
 }
 
 // NewApp creates a new App application struct
 func NewApp(v string) *App {
 	return &App{
-		version:        v,
-		networkService: services.NewNetworkService(),
-		updateService:  services.NewUpdateService(v),
-		watcherService: services.NewWatcherService(),
-		pingTool:       tools.NewPingTool(),
+		version:         v,
+		networkService:  services.NewNetworkService(),
+		updateService:   services.NewUpdateService(v),
+		watcherService:  services.NewWatcherService(),
+		settingsService: services.NewSettingsService(),
+		wifiService:     services.NewWifiService(services.NewWifiBackend()),
+		dhcpServer:      services.NewDHCPServerService(),
+		mavlinkScan:     services.NewMAVLinkDiscoveryService(),
+		mdnsService:     services.NewMDNSService(),
+		pingTool:        tools.NewPingTool(),
+		scanTool:        tools.NewScanTool(),
 	}
 }
 
@@ -43,10 +68,50 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.updateService.SetContext(ctx)
 	a.watcherService.SetContext(ctx)
+	a.networkService.SetContext(ctx)
+	a.wifiService.SetContext(ctx)
+	a.dhcpServer.SetContext(ctx)
+	a.mavlinkScan.SetContext(ctx)
+	a.mdnsService.SetContext(ctx)
+
+	if err := a.settingsService.Load(); err != nil {
+		runtime.LogError(ctx, "Settings: "+err.Error())
+	}
+	a.applySettings(a.settingsService.Get())
 
 	go a.networkService.StartLiveLoop(ctx)
 }
 
+// applySettings pushes persisted preferences into the services that own them.
+func (a *App) applySettings(s settings.Settings) {
+	a.watcherService.SaveConfig(s.Watcher)
+	a.watcherService.SetHistoryLimits(s.History)
+	a.dhcpServer.RestoreConfig(s.DHCPServer)
+	a.mavlinkScan.RestoreConfig(s.MAVLinkDiscovery)
+}
+
+func (a *App) saveSettings(fn func(*settings.Settings)) {
+	if err := a.settingsService.Update(fn); err != nil {
+		runtime.LogError(a.ctx, "Settings: "+err.Error())
+	}
+}
+
+func (a *App) GetSettings() settings.Settings {
+	return a.settingsService.Get()
+}
+
+func (a *App) SaveSettings(s settings.Settings) string {
+	if err := a.settingsService.Set(s); err != nil {
+		return err.Error()
+	}
+	a.applySettings(a.settingsService.Get())
+	return ""
+}
+
+func (a *App) GetInterfaces() []network.HardwareInterface {
+	return a.networkService.GetInterfaces()
+}
+
 func (a *App) CreateInterface(hardwarePortName string, newServiceName string) string {
 	return a.networkService.CreateInterface(hardwarePortName, newServiceName)
 }
@@ -59,6 +124,309 @@ func (a *App) UpdateInterface(data network.UpdatePayload) string {
 	return a.networkService.UpdateInterface(data)
 }
 
+// UpdateInterfaceWithConfirm applies the update and reverts it after
+// opts.Timeout seconds unless ConfirmNetworkChange is called or
+// opts.PingTarget answers first.
+func (a *App) UpdateInterfaceWithConfirm(data network.UpdatePayload, opts network.ConfirmOptions) string {
+	return a.networkService.UpdateInterfaceWithConfirm(data, opts)
+}
+
+func (a *App) ConfirmNetworkChange() string {
+	return a.networkService.ConfirmChange()
+}
+
+func (a *App) RevertNetworkChange() string {
+	return a.networkService.RevertChange()
+}
+
+func (a *App) GetPendingNetworkChange() *network.PendingChange {
+	return a.networkService.GetPendingChange()
+}
+
+func (a *App) AddAlias(serviceName string, ip string, mask string) string {
+	return a.networkService.AddAlias(serviceName, ip, mask)
+}
+
+func (a *App) RemoveAlias(serviceName string, ip string) string {
+	return a.networkService.RemoveAlias(serviceName, ip)
+}
+
+func (a *App) ListRoutes() []network.Route {
+	return a.networkService.ListRoutes()
+}
+
+func (a *App) AddRoute(serviceName string, route network.Route) string {
+	return a.networkService.AddRoute(serviceName, route)
+}
+
+func (a *App) RemoveRoute(serviceName string, route network.Route) string {
+	return a.networkService.RemoveRoute(serviceName, route)
+}
+
+func (a *App) SetServiceEnabled(serviceName string, enabled bool) string {
+	return a.networkService.SetServiceEnabled(serviceName, enabled)
+}
+
+func (a *App) SetInterfaceEnabled(device string, enabled bool) string {
+	return a.networkService.SetInterfaceEnabled(device, enabled)
+}
+
+func (a *App) SetServiceOrder(names []string) string {
+	return a.networkService.SetServiceOrder(names)
+}
+
+func (a *App) SetMTU(device string, mtu int) string {
+	return a.networkService.SetMTU(device, mtu)
+}
+
+func (a *App) SetMedia(device string, media string) string {
+	return a.networkService.SetMedia(device, media)
+}
+
+func (a *App) GetMediaOptions(device string) []string {
+	return a.networkService.GetMediaOptions(device)
+}
+
+func (a *App) ListVLANs() []network.VLAN {
+	return a.networkService.ListVLANs()
+}
+
+func (a *App) CreateVLAN(vlan network.VLAN) string {
+	return a.networkService.CreateVLAN(vlan)
+}
+
+func (a *App) DeleteVLAN(name string) string {
+	return a.networkService.DeleteVLAN(name)
+}
+
+func (a *App) GetWifiInterfaces() []string {
+	return a.wifiService.GetInterfaces()
+}
+
+func (a *App) ScanWifi(iface string) wifi.ScanResult {
+	return a.wifiService.Scan(iface)
+}
+
+func (a *App) GetWifiStatus(iface string) wifi.Status {
+	return a.wifiService.GetStatus(iface)
+}
+
+func (a *App) JoinWifi(iface string, req wifi.JoinRequest) string {
+	return a.wifiService.Join(iface, req)
+}
+
+func (a *App) LeaveWifi(iface string) string {
+	return a.wifiService.Leave(iface)
+}
+
+func (a *App) GetDHCPClientLease(serviceName string) dhcp.ClientLease {
+	return a.networkService.GetDHCPLease(serviceName)
+}
+
+func (a *App) RenewDHCPClientLease(serviceName string) string {
+	return a.networkService.RenewDHCPLease(serviceName)
+}
+
+func (a *App) ReleaseDHCPClientLease(serviceName string) string {
+	return a.networkService.ReleaseDHCPLease(serviceName)
+}
+
+func (a *App) DiscoverDHCPServers(device string) dhcp.DiscoverResult {
+	return a.networkService.DiscoverDHCPServers(device)
+}
+
+func (a *App) GetDHCPServerState() dhcp.ServerState {
+	return a.dhcpServer.GetState()
+}
+
+func (a *App) SaveDHCPServerConfig(cfg dhcp.ServerConfig) string {
+	if errMsg := a.dhcpServer.SaveConfig(cfg); errMsg != "" {
+		return errMsg
+	}
+	a.saveSettings(func(s *settings.Settings) {
+		s.DHCPServer = a.dhcpServer.GetState().Config
+	})
+	return ""
+}
+
+func (a *App) StartDHCPServer(cfg dhcp.ServerConfig) string {
+	if errMsg := a.dhcpServer.Start(cfg); errMsg != "" {
+		return errMsg
+	}
+	a.saveSettings(func(s *settings.Settings) {
+		s.DHCPServer = a.dhcpServer.GetState().Config
+	})
+	return ""
+}
+
+func (a *App) StopDHCPServer() {
+	a.dhcpServer.Stop()
+}
+
+func (a *App) ReleaseDHCPLease(mac string) string {
+	return a.dhcpServer.ReleaseLease(mac)
+}
+
+func (a *App) GetMAVLinkDiscoveryState() mavlink.DiscoveryState {
+	return a.mavlinkScan.GetState()
+}
+
+// StartMAVLinkDiscovery listens until StopMAVLinkDiscovery. Vehicles
+// arrive as "mavlink-vehicle" events, one per HEARTBEAT.
+func (a *App) StartMAVLinkDiscovery(cfg mavlink.DiscoveryConfig) string {
+	if errMsg := a.mavlinkScan.Start(cfg); errMsg != "" {
+		return errMsg
+	}
+	a.saveSettings(func(s *settings.Settings) {
+		s.MAVLinkDiscovery = cfg
+	})
+	return ""
+}
+
+func (a *App) StopMAVLinkDiscovery() {
+	a.mavlinkScan.Stop()
+}
+
+func (a *App) GetMDNSState() mdns.BrowserState {
+	return a.mdnsService.GetState()
+}
+
+func (a *App) BrowseMDNS(req mdns.BrowseRequest) string {
+	return a.mdnsService.Browse(req)
+}
+
+func (a *App) StopMDNSBrowse() {
+	a.mdnsService.StopBrowse()
+}
+
+func (a *App) RegisterMDNSService(r mdns.Registration) string {
+	return a.mdnsService.Register(r)
+}
+
+func (a *App) UnregisterMDNSService(instance, serviceType string) string {
+	return a.mdnsService.Unregister(instance, serviceType)
+}
+
+func (a *App) ValidateInterfaceUpdate(data network.UpdatePayload) []network.FieldError {
+	return a.networkService.ValidateUpdate(data)
+}
+
+func (a *App) GetProfiles() []network.Profile {
+	return a.settingsService.Profiles()
+}
+
+// CaptureProfile saves the current configuration of the given hardware
+// ports (all ports if empty) under name, replacing a profile of that name.
+func (a *App) CaptureProfile(name string, hardwarePorts []string) string {
+	profile := a.networkService.CaptureProfile(name, hardwarePorts)
+	return a.SaveProfile(profile)
+}
+
+func (a *App) SaveProfile(profile network.Profile) string {
+	if errs := profile.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+	profile.UpdatedAt = time.Now()
+
+	if err := a.settingsService.SaveProfile(profile); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (a *App) DeleteProfile(name string) string {
+	if err := a.settingsService.DeleteProfile(name); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (a *App) DiffProfile(name string) []network.ProfileChange {
+	profile, ok := a.settingsService.Profile(name)
+	if !ok {
+		return []network.ProfileChange{}
+	}
+	return a.networkService.DiffProfile(profile)
+}
+
+func (a *App) ApplyProfile(name string) string {
+	profile, ok := a.settingsService.Profile(name)
+	if !ok {
+		return "Profile not found: " + name
+	}
+	return a.networkService.ApplyProfile(profile)
+}
+
+func (a *App) ApplyProfileWithConfirm(name string, opts network.ConfirmOptions) string {
+	profile, ok := a.settingsService.Profile(name)
+	if !ok {
+		return "Profile not found: " + name
+	}
+	return a.networkService.ApplyProfileWithConfirm(profile, opts)
+}
+
+// ExportProfiles writes the named profiles (all if empty) to a JSON file
+// picked by the user.
+func (a *App) ExportProfiles(names []string) string {
+	profiles := []network.Profile{}
+	for _, p := range a.settingsService.Profiles() {
+		if len(names) == 0 || slices.Contains(names, p.Name) {
+			profiles = append(profiles, p)
+		}
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export network profiles",
+		DefaultFilename: "macbox-profiles.json",
+	})
+	if err != nil {
+		return err.Error()
+	}
+	if path == "" {
+		return ""
+	}
+
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// ImportProfiles reads profiles from a JSON file picked by the user,
+// replacing existing profiles with the same names.
+func (a *App) ImportProfiles() string {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import network profiles",
+	})
+	if err != nil {
+		return err.Error()
+	}
+	if path == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err.Error()
+	}
+
+	var profiles []network.Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return "Invalid profiles file: " + err.Error()
+	}
+
+	for _, p := range profiles {
+		if msg := a.SaveProfile(p); msg != "" {
+			return p.Name + ": " + msg
+		}
+	}
+	return ""
+}
+
 func (a *App) RegisterModels() network.HardwareInterface {
 	return network.HardwareInterface{}
 }
@@ -68,7 +436,17 @@ func (a *App) RegisterUDPPacket() watcher.UDPPacket {
 }
 
 func (a *App) CheckUpdate() *services.ReleaseInfo {
-	return a.updateService.CheckForUpdates()
+	release := a.updateService.CheckForUpdates()
+	if release != nil && release.TagName == a.settingsService.Get().Update.SkippedVersion {
+		return nil
+	}
+	return release
+}
+
+func (a *App) SkipUpdate(version string) {
+	a.saveSettings(func(s *settings.Settings) {
+		s.Update.SkippedVersion = version
+	})
 }
 
 func (a *App) InstallUpdate(release *services.ReleaseInfo) string {
@@ -83,6 +461,10 @@ func (a *App) StartPing(ip string, count int) string {
 		return err.Error()
 	}
 
+	a.saveSettings(func(s *settings.Settings) {
+		s.Ping.Target = ip
+		s.Ping.Count = count
+	})
 	return ""
 }
 
@@ -90,6 +472,42 @@ func (a *App) StopPing() {
 	a.pingTool.Stop()
 }
 
+// StartScan sweeps the subnet of req.Service, or req.CIDR, and returns
+// when it is done. Live hosts arrive as "scan-host" events, the UI
+// replaces an earlier host with the same IP.
+func (a *App) StartScan(req scan.Request) string {
+	if errs := req.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+
+	var prefix netip.Prefix
+	if req.Service != "" {
+		var errMsg string
+		if prefix, errMsg = a.networkService.ServiceSubnet(req.Service); errMsg != "" {
+			return errMsg
+		}
+		if scan.HostCount(prefix) > scan.MaxHosts {
+			return fmt.Sprintf("%s is on %s, scans are limited to %d addresses.", req.Service, prefix, scan.MaxHosts)
+		}
+	} else {
+		prefix = netip.MustParsePrefix(req.CIDR)
+	}
+
+	err := a.scanTool.Start(a.ctx, prefix, req, func(host scan.Host) {
+		runtime.EventsEmit(a.ctx, "scan-host", host)
+	}, func(p scan.Progress) {
+		runtime.EventsEmit(a.ctx, "scan-progress", p)
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (a *App) StopScan() {
+	a.scanTool.Stop()
+}
+
 func (a *App) GetAvailableParsers() []watcher.ParserMeta {
 	return a.watcherService.GetAvailableParsers()
 }
@@ -100,6 +518,9 @@ func (a *App) GetWatcherState() watcher.WatcherState {
 
 func (a *App) SaveWatcherConfig(cfg watcher.WatcherConfig) {
 	a.watcherService.SaveConfig(cfg)
+	a.saveSettings(func(s *settings.Settings) {
+		s.Watcher = cfg
+	})
 }
 
 func (a *App) StartWatcher() {
@@ -109,3 +530,47 @@ func (a *App) StartWatcher() {
 func (a *App) StopWatcher() {
 	a.watcherService.Stop()
 }
+
+func (a *App) QueryPackets(q watcher.PacketQuery) watcher.PacketPage {
+	return a.watcherService.QueryPackets(q)
+}
+
+func (a *App) GetPacket(id int64) *watcher.UDPPacket {
+	if p, ok := a.watcherService.GetPacket(id); ok {
+		return &p
+	}
+	return nil
+}
+
+func (a *App) GetPacketHistoryStats() watcher.HistoryStats {
+	return a.watcherService.GetHistoryStats()
+}
+
+func (a *App) SetPacketHistoryLimits(limits watcher.HistoryLimits) {
+	a.watcherService.SetHistoryLimits(limits)
+	a.saveSettings(func(s *settings.Settings) {
+		s.History = limits
+	})
+}
+
+func (a *App) ClearPacketHistory() {
+	a.watcherService.ClearHistory()
+}
+
+func (a *App) ExportPackets(req watcher.ExportRequest) watcher.ExportResult {
+	if req.Path == "" {
+		path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export packets",
+			DefaultFilename: "packets" + export.Extension(req.Format),
+		})
+		if err != nil {
+			return watcher.ExportResult{Error: err.Error()}
+		}
+		if path == "" {
+			return watcher.ExportResult{}
+		}
+		req.Path = path
+	}
+
+	return a.watcherService.ExportPackets(req)
+}
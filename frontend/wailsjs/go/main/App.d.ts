@@ -1,35 +1,165 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {network} from '../models';
+import {mdns} from '../models';
 import {services} from '../models';
+import {dhcp} from '../models';
 import {watcher} from '../models';
-import {network} from '../models';
+import {mavlink} from '../models';
+import {settings} from '../models';
+import {wifi} from '../models';
+import {scan} from '../models';
+
+export function AddAlias(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function AddRoute(arg1:string,arg2:network.Route):Promise<string>;
+
+export function ApplyProfile(arg1:string):Promise<string>;
+
+export function ApplyProfileWithConfirm(arg1:string,arg2:network.ConfirmOptions):Promise<string>;
+
+export function BrowseMDNS(arg1:mdns.BrowseRequest):Promise<string>;
+
+export function CaptureProfile(arg1:string,arg2:Array<string>):Promise<string>;
 
 export function CheckUpdate():Promise<services.ReleaseInfo>;
 
+export function ClearPacketHistory():Promise<void>;
+
+export function ConfirmNetworkChange():Promise<string>;
+
 export function CreateInterface(arg1:string,arg2:string):Promise<string>;
 
+export function CreateVLAN(arg1:network.VLAN):Promise<string>;
+
 export function DeleteInterface(arg1:string):Promise<string>;
 
+export function DeleteProfile(arg1:string):Promise<string>;
+
+export function DeleteVLAN(arg1:string):Promise<string>;
+
+export function DiffProfile(arg1:string):Promise<Array<network.ProfileChange>>;
+
+export function DiscoverDHCPServers(arg1:string):Promise<dhcp.DiscoverResult>;
+
+export function ExportPackets(arg1:watcher.ExportRequest):Promise<watcher.ExportResult>;
+
+export function ExportProfiles(arg1:Array<string>):Promise<string>;
+
 export function GetAppVersion():Promise<string>;
 
 export function GetAvailableParsers():Promise<Array<watcher.ParserMeta>>;
 
+export function GetDHCPClientLease(arg1:string):Promise<dhcp.ClientLease>;
+
+export function GetDHCPServerState():Promise<dhcp.ServerState>;
+
+export function GetInterfaces():Promise<Array<network.HardwareInterface>>;
+
+export function GetMAVLinkDiscoveryState():Promise<mavlink.DiscoveryState>;
+
+export function GetMDNSState():Promise<mdns.BrowserState>;
+
+export function GetMediaOptions(arg1:string):Promise<Array<string>>;
+
+export function GetPacket(arg1:number):Promise<watcher.UDPPacket>;
+
+export function GetPacketHistoryStats():Promise<watcher.HistoryStats>;
+
+export function GetPendingNetworkChange():Promise<network.PendingChange>;
+
+export function GetProfiles():Promise<Array<network.Profile>>;
+
+export function GetSettings():Promise<settings.Settings>;
+
 export function GetWatcherState():Promise<watcher.WatcherState>;
 
+export function GetWifiInterfaces():Promise<Array<string>>;
+
+export function GetWifiStatus(arg1:string):Promise<wifi.Status>;
+
+export function ImportProfiles():Promise<string>;
+
 export function InstallUpdate(arg1:services.ReleaseInfo):Promise<string>;
 
+export function JoinWifi(arg1:string,arg2:wifi.JoinRequest):Promise<string>;
+
+export function LeaveWifi(arg1:string):Promise<string>;
+
+export function ListRoutes():Promise<Array<network.Route>>;
+
+export function ListVLANs():Promise<Array<network.VLAN>>;
+
+export function QueryPackets(arg1:watcher.PacketQuery):Promise<watcher.PacketPage>;
+
+export function RegisterMDNSService(arg1:mdns.Registration):Promise<string>;
+
 export function RegisterModels():Promise<network.HardwareInterface>;
 
 export function RegisterUDPPacket():Promise<watcher.UDPPacket>;
 
+export function ReleaseDHCPClientLease(arg1:string):Promise<string>;
+
+export function ReleaseDHCPLease(arg1:string):Promise<string>;
+
+export function RemoveAlias(arg1:string,arg2:string):Promise<string>;
+
+export function RemoveRoute(arg1:string,arg2:network.Route):Promise<string>;
+
+export function RenewDHCPClientLease(arg1:string):Promise<string>;
+
+export function RevertNetworkChange():Promise<string>;
+
+export function SaveDHCPServerConfig(arg1:dhcp.ServerConfig):Promise<string>;
+
+export function SaveProfile(arg1:network.Profile):Promise<string>;
+
+export function SaveSettings(arg1:settings.Settings):Promise<string>;
+
 export function SaveWatcherConfig(arg1:watcher.WatcherConfig):Promise<void>;
 
+export function ScanWifi(arg1:string):Promise<wifi.ScanResult>;
+
+export function SetInterfaceEnabled(arg1:string,arg2:boolean):Promise<string>;
+
+export function SetMTU(arg1:string,arg2:number):Promise<string>;
+
+export function SetMedia(arg1:string,arg2:string):Promise<string>;
+
+export function SetPacketHistoryLimits(arg1:watcher.HistoryLimits):Promise<void>;
+
+export function SetServiceEnabled(arg1:string,arg2:boolean):Promise<string>;
+
+export function SetServiceOrder(arg1:Array<string>):Promise<string>;
+
+export function SkipUpdate(arg1:string):Promise<void>;
+
+export function StartDHCPServer(arg1:dhcp.ServerConfig):Promise<string>;
+
+export function StartMAVLinkDiscovery(arg1:mavlink.DiscoveryConfig):Promise<string>;
+
 export function StartPing(arg1:string,arg2:number):Promise<string>;
 
+export function StartScan(arg1:scan.Request):Promise<string>;
+
 export function StartWatcher():Promise<void>;
 
+export function StopDHCPServer():Promise<void>;
+
+export function StopMAVLinkDiscovery():Promise<void>;
+
+export function StopMDNSBrowse():Promise<void>;
+
 export function StopPing():Promise<void>;
 
+export function StopScan():Promise<void>;
+
 export function StopWatcher():Promise<void>;
 
+export function UnregisterMDNSService(arg1:string,arg2:string):Promise<string>;
+
 export function UpdateInterface(arg1:network.UpdatePayload):Promise<string>;
+
+export function UpdateInterfaceWithConfirm(arg1:network.UpdatePayload,arg2:network.ConfirmOptions):Promise<string>;
+
+export function ValidateInterfaceUpdate(arg1:network.UpdatePayload):Promise<Array<network.FieldError>>;
@@ -2,18 +2,78 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAlias(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddAlias'](arg1, arg2, arg3);
+}
+
+export function AddRoute(arg1, arg2) {
+  return window['go']['main']['App']['AddRoute'](arg1, arg2);
+}
+
+export function ApplyProfile(arg1) {
+  return window['go']['main']['App']['ApplyProfile'](arg1);
+}
+
+export function ApplyProfileWithConfirm(arg1, arg2) {
+  return window['go']['main']['App']['ApplyProfileWithConfirm'](arg1, arg2);
+}
+
+export function BrowseMDNS(arg1) {
+  return window['go']['main']['App']['BrowseMDNS'](arg1);
+}
+
+export function CaptureProfile(arg1, arg2) {
+  return window['go']['main']['App']['CaptureProfile'](arg1, arg2);
+}
+
 export function CheckUpdate() {
   return window['go']['main']['App']['CheckUpdate']();
 }
 
+export function ClearPacketHistory() {
+  return window['go']['main']['App']['ClearPacketHistory']();
+}
+
+export function ConfirmNetworkChange() {
+  return window['go']['main']['App']['ConfirmNetworkChange']();
+}
+
 export function CreateInterface(arg1, arg2) {
   return window['go']['main']['App']['CreateInterface'](arg1, arg2);
 }
 
+export function CreateVLAN(arg1) {
+  return window['go']['main']['App']['CreateVLAN'](arg1);
+}
+
 export function DeleteInterface(arg1) {
   return window['go']['main']['App']['DeleteInterface'](arg1);
 }
 
+export function DeleteProfile(arg1) {
+  return window['go']['main']['App']['DeleteProfile'](arg1);
+}
+
+export function DeleteVLAN(arg1) {
+  return window['go']['main']['App']['DeleteVLAN'](arg1);
+}
+
+export function DiffProfile(arg1) {
+  return window['go']['main']['App']['DiffProfile'](arg1);
+}
+
+export function DiscoverDHCPServers(arg1) {
+  return window['go']['main']['App']['DiscoverDHCPServers'](arg1);
+}
+
+export function ExportPackets(arg1) {
+  return window['go']['main']['App']['ExportPackets'](arg1);
+}
+
+export function ExportProfiles(arg1) {
+  return window['go']['main']['App']['ExportProfiles'](arg1);
+}
+
 export function GetAppVersion() {
   return window['go']['main']['App']['GetAppVersion']();
 }
@@ -22,14 +82,94 @@ export function GetAvailableParsers() {
   return window['go']['main']['App']['GetAvailableParsers']();
 }
 
+export function GetDHCPClientLease(arg1) {
+  return window['go']['main']['App']['GetDHCPClientLease'](arg1);
+}
+
+export function GetDHCPServerState() {
+  return window['go']['main']['App']['GetDHCPServerState']();
+}
+
+export function GetInterfaces() {
+  return window['go']['main']['App']['GetInterfaces']();
+}
+
+export function GetMAVLinkDiscoveryState() {
+  return window['go']['main']['App']['GetMAVLinkDiscoveryState']();
+}
+
+export function GetMDNSState() {
+  return window['go']['main']['App']['GetMDNSState']();
+}
+
+export function GetMediaOptions(arg1) {
+  return window['go']['main']['App']['GetMediaOptions'](arg1);
+}
+
+export function GetPacket(arg1) {
+  return window['go']['main']['App']['GetPacket'](arg1);
+}
+
+export function GetPacketHistoryStats() {
+  return window['go']['main']['App']['GetPacketHistoryStats']();
+}
+
+export function GetPendingNetworkChange() {
+  return window['go']['main']['App']['GetPendingNetworkChange']();
+}
+
+export function GetProfiles() {
+  return window['go']['main']['App']['GetProfiles']();
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
 export function GetWatcherState() {
   return window['go']['main']['App']['GetWatcherState']();
 }
 
+export function GetWifiInterfaces() {
+  return window['go']['main']['App']['GetWifiInterfaces']();
+}
+
+export function GetWifiStatus(arg1) {
+  return window['go']['main']['App']['GetWifiStatus'](arg1);
+}
+
+export function ImportProfiles() {
+  return window['go']['main']['App']['ImportProfiles']();
+}
+
 export function InstallUpdate(arg1) {
   return window['go']['main']['App']['InstallUpdate'](arg1);
 }
 
+export function JoinWifi(arg1, arg2) {
+  return window['go']['main']['App']['JoinWifi'](arg1, arg2);
+}
+
+export function LeaveWifi(arg1) {
+  return window['go']['main']['App']['LeaveWifi'](arg1);
+}
+
+export function ListRoutes() {
+  return window['go']['main']['App']['ListRoutes']();
+}
+
+export function ListVLANs() {
+  return window['go']['main']['App']['ListVLANs']();
+}
+
+export function QueryPackets(arg1) {
+  return window['go']['main']['App']['QueryPackets'](arg1);
+}
+
+export function RegisterMDNSService(arg1) {
+  return window['go']['main']['App']['RegisterMDNSService'](arg1);
+}
+
 export function RegisterModels() {
   return window['go']['main']['App']['RegisterModels']();
 }
@@ -38,26 +178,134 @@ export function RegisterUDPPacket() {
   return window['go']['main']['App']['RegisterUDPPacket']();
 }
 
+export function ReleaseDHCPClientLease(arg1) {
+  return window['go']['main']['App']['ReleaseDHCPClientLease'](arg1);
+}
+
+export function ReleaseDHCPLease(arg1) {
+  return window['go']['main']['App']['ReleaseDHCPLease'](arg1);
+}
+
+export function RemoveAlias(arg1, arg2) {
+  return window['go']['main']['App']['RemoveAlias'](arg1, arg2);
+}
+
+export function RemoveRoute(arg1, arg2) {
+  return window['go']['main']['App']['RemoveRoute'](arg1, arg2);
+}
+
+export function RenewDHCPClientLease(arg1) {
+  return window['go']['main']['App']['RenewDHCPClientLease'](arg1);
+}
+
+export function RevertNetworkChange() {
+  return window['go']['main']['App']['RevertNetworkChange']();
+}
+
+export function SaveDHCPServerConfig(arg1) {
+  return window['go']['main']['App']['SaveDHCPServerConfig'](arg1);
+}
+
+export function SaveProfile(arg1) {
+  return window['go']['main']['App']['SaveProfile'](arg1);
+}
+
+export function SaveSettings(arg1) {
+  return window['go']['main']['App']['SaveSettings'](arg1);
+}
+
 export function SaveWatcherConfig(arg1) {
   return window['go']['main']['App']['SaveWatcherConfig'](arg1);
 }
 
+export function ScanWifi(arg1) {
+  return window['go']['main']['App']['ScanWifi'](arg1);
+}
+
+export function SetInterfaceEnabled(arg1, arg2) {
+  return window['go']['main']['App']['SetInterfaceEnabled'](arg1, arg2);
+}
+
+export function SetMTU(arg1, arg2) {
+  return window['go']['main']['App']['SetMTU'](arg1, arg2);
+}
+
+export function SetMedia(arg1, arg2) {
+  return window['go']['main']['App']['SetMedia'](arg1, arg2);
+}
+
+export function SetPacketHistoryLimits(arg1) {
+  return window['go']['main']['App']['SetPacketHistoryLimits'](arg1);
+}
+
+export function SetServiceEnabled(arg1, arg2) {
+  return window['go']['main']['App']['SetServiceEnabled'](arg1, arg2);
+}
+
+export function SetServiceOrder(arg1) {
+  return window['go']['main']['App']['SetServiceOrder'](arg1);
+}
+
+export function SkipUpdate(arg1) {
+  return window['go']['main']['App']['SkipUpdate'](arg1);
+}
+
+export function StartDHCPServer(arg1) {
+  return window['go']['main']['App']['StartDHCPServer'](arg1);
+}
+
+export function StartMAVLinkDiscovery(arg1) {
+  return window['go']['main']['App']['StartMAVLinkDiscovery'](arg1);
+}
+
 export function StartPing(arg1, arg2) {
   return window['go']['main']['App']['StartPing'](arg1, arg2);
 }
 
+export function StartScan(arg1) {
+  return window['go']['main']['App']['StartScan'](arg1);
+}
+
 export function StartWatcher() {
   return window['go']['main']['App']['StartWatcher']();
 }
 
+export function StopDHCPServer() {
+  return window['go']['main']['App']['StopDHCPServer']();
+}
+
+export function StopMAVLinkDiscovery() {
+  return window['go']['main']['App']['StopMAVLinkDiscovery']();
+}
+
+export function StopMDNSBrowse() {
+  return window['go']['main']['App']['StopMDNSBrowse']();
+}
+
 export function StopPing() {
   return window['go']['main']['App']['StopPing']();
 }
 
+export function StopScan() {
+  return window['go']['main']['App']['StopScan']();
+}
+
 export function StopWatcher() {
   return window['go']['main']['App']['StopWatcher']();
 }
 
+export function UnregisterMDNSService(arg1, arg2) {
+  return window['go']['main']['App']['UnregisterMDNSService'](arg1, arg2);
+}
+
 export function UpdateInterface(arg1) {
   return window['go']['main']['App']['UpdateInterface'](arg1);
 }
+
+export function UpdateInterfaceWithConfirm(arg1, arg2) {
+  return window['go']['main']['App']['UpdateInterfaceWithConfirm'](arg1, arg2);
+}
+
+export function ValidateInterfaceUpdate(arg1) {
+  return window['go']['main']['App']['ValidateInterfaceUpdate'](arg1);
+}
@@ -1,47 +1,1159 @@
+export namespace dhcp {
+	
+	export class Option {
+	    code: number;
+	    name: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Option(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.name = source["name"];
+	        this.value = source["value"];
+	    }
+	}
+	export class ClientLease {
+	    service: string;
+	    device: string;
+	    source: string;
+	    ip: string;
+	    subnetMask: string;
+	    router: string;
+	    dns: string[];
+	    domainName: string;
+	    server: string;
+	    leaseTime: number;
+	    // Go type: time
+	    expires: any;
+	    options: Option[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClientLease(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.service = source["service"];
+	        this.device = source["device"];
+	        this.source = source["source"];
+	        this.ip = source["ip"];
+	        this.subnetMask = source["subnetMask"];
+	        this.router = source["router"];
+	        this.dns = source["dns"];
+	        this.domainName = source["domainName"];
+	        this.server = source["server"];
+	        this.leaseTime = source["leaseTime"];
+	        this.expires = this.convertValues(source["expires"], null);
+	        this.options = this.convertValues(source["options"], Option);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Offer {
+	    server: string;
+	    from: string;
+	    offeredIp: string;
+	    subnetMask: string;
+	    router: string;
+	    dns: string[];
+	    leaseTime: number;
+	    current: boolean;
+	    options: Option[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Offer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server = source["server"];
+	        this.from = source["from"];
+	        this.offeredIp = source["offeredIp"];
+	        this.subnetMask = source["subnetMask"];
+	        this.router = source["router"];
+	        this.dns = source["dns"];
+	        this.leaseTime = source["leaseTime"];
+	        this.current = source["current"];
+	        this.options = this.convertValues(source["options"], Option);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiscoverResult {
+	    device: string;
+	    offers: Offer[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscoverResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.device = source["device"];
+	        this.offers = this.convertValues(source["offers"], Offer);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Lease {
+	    mac: string;
+	    ip: string;
+	    hostname: string;
+	    state: string;
+	    static: boolean;
+	    // Go type: time
+	    expires: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Lease(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mac = source["mac"];
+	        this.ip = source["ip"];
+	        this.hostname = source["hostname"];
+	        this.state = source["state"];
+	        this.static = source["static"];
+	        this.expires = this.convertValues(source["expires"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class Reservation {
+	    mac: string;
+	    ip: string;
+	    hostname: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Reservation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mac = source["mac"];
+	        this.ip = source["ip"];
+	        this.hostname = source["hostname"];
+	    }
+	}
+	export class ServerConfig {
+	    device: string;
+	    serverIp: string;
+	    subnetMask: string;
+	    poolStart: string;
+	    poolEnd: string;
+	    router: string;
+	    dns: string[];
+	    leaseTime: number;
+	    reservations: Reservation[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.device = source["device"];
+	        this.serverIp = source["serverIp"];
+	        this.subnetMask = source["subnetMask"];
+	        this.poolStart = source["poolStart"];
+	        this.poolEnd = source["poolEnd"];
+	        this.router = source["router"];
+	        this.dns = source["dns"];
+	        this.leaseTime = source["leaseTime"];
+	        this.reservations = this.convertValues(source["reservations"], Reservation);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ServerState {
+	    config: ServerConfig;
+	    running: boolean;
+	    error: string;
+	    leases: Lease[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.config = this.convertValues(source["config"], ServerConfig);
+	        this.running = source["running"];
+	        this.error = source["error"];
+	        this.leases = this.convertValues(source["leases"], Lease);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace mavlink {
+	
+	export class DiscoveryConfig {
+	    udpPorts: number[];
+	    tcpAddresses: string[];
+	    broadcast: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscoveryConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.udpPorts = source["udpPorts"];
+	        this.tcpAddresses = source["tcpAddresses"];
+	        this.broadcast = source["broadcast"];
+	    }
+	}
+	export class Vehicle {
+	    systemId: number;
+	    componentId: number;
+	    transport: string;
+	    source: string;
+	    endpoint: string;
+	    autopilot: string;
+	    type: string;
+	    state: string;
+	    armed: boolean;
+	    firmware: string;
+	    mavlinkVersion: number;
+	    // Go type: time
+	    lastSeen: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Vehicle(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.systemId = source["systemId"];
+	        this.componentId = source["componentId"];
+	        this.transport = source["transport"];
+	        this.source = source["source"];
+	        this.endpoint = source["endpoint"];
+	        this.autopilot = source["autopilot"];
+	        this.type = source["type"];
+	        this.state = source["state"];
+	        this.armed = source["armed"];
+	        this.firmware = source["firmware"];
+	        this.mavlinkVersion = source["mavlinkVersion"];
+	        this.lastSeen = this.convertValues(source["lastSeen"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Endpoint {
+	    transport: string;
+	    address: string;
+	    connected: boolean;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Endpoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.transport = source["transport"];
+	        this.address = source["address"];
+	        this.connected = source["connected"];
+	        this.error = source["error"];
+	    }
+	}
+	export class DiscoveryState {
+	    config: DiscoveryConfig;
+	    running: boolean;
+	    endpoints: Endpoint[];
+	    vehicles: Vehicle[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscoveryState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.config = this.convertValues(source["config"], DiscoveryConfig);
+	        this.running = source["running"];
+	        this.endpoints = this.convertValues(source["endpoints"], Endpoint);
+	        this.vehicles = this.convertValues(source["vehicles"], Vehicle);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
+export namespace mdns {
+	
+	export class BrowseRequest {
+	    types: string[];
+	    domain: string;
+	    interface: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BrowseRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.types = source["types"];
+	        this.domain = source["domain"];
+	        this.interface = source["interface"];
+	    }
+	}
+	export class Registration {
+	    instance: string;
+	    type: string;
+	    port: number;
+	    txt: string[];
+	    interface: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Registration(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instance = source["instance"];
+	        this.type = source["type"];
+	        this.port = source["port"];
+	        this.txt = source["txt"];
+	        this.interface = source["interface"];
+	    }
+	}
+	export class Service {
+	    instance: string;
+	    type: string;
+	    domain: string;
+	    hostName: string;
+	    port: number;
+	    txt: string[];
+	    ipv4: string[];
+	    ipv6: string[];
+	    // Go type: time
+	    expires: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Service(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instance = source["instance"];
+	        this.type = source["type"];
+	        this.domain = source["domain"];
+	        this.hostName = source["hostName"];
+	        this.port = source["port"];
+	        this.txt = source["txt"];
+	        this.ipv4 = source["ipv4"];
+	        this.ipv6 = source["ipv6"];
+	        this.expires = this.convertValues(source["expires"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BrowserState {
+	    browsing: boolean;
+	    request: BrowseRequest;
+	    types: string[];
+	    services: Service[];
+	    registrations: Registration[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BrowserState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.browsing = source["browsing"];
+	        this.request = this.convertValues(source["request"], BrowseRequest);
+	        this.types = source["types"];
+	        this.services = this.convertValues(source["services"], Service);
+	        this.registrations = this.convertValues(source["registrations"], Registration);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
 export namespace network {
 	
-	export class LogicInterface {
-	    id: string;
-	    name: string;
-	    device: string;
-	    ip: string;
-	    mask: string;
-	    gateway: string;
-	    method: string;
+	export class ConfirmOptions {
+	    timeout: number;
+	    pingTarget: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfirmOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timeout = source["timeout"];
+	        this.pingTarget = source["pingTarget"];
+	    }
+	}
+	export class FieldError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class Route {
+	    destination: string;
+	    mask: string;
+	    gateway: string;
+	    device: string;
+	    persistent: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Route(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.destination = source["destination"];
+	        this.mask = source["mask"];
+	        this.gateway = source["gateway"];
+	        this.device = source["device"];
+	        this.persistent = source["persistent"];
+	    }
+	}
+	export class IPv6Address {
+	    ip: string;
+	    prefixLength: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new IPv6Address(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ip = source["ip"];
+	        this.prefixLength = source["prefixLength"];
+	    }
+	}
+	export class IPv6Config {
+	    method: string;
+	    addresses: IPv6Address[];
+	    router: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IPv6Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.method = source["method"];
+	        this.addresses = this.convertValues(source["addresses"], IPv6Address);
+	        this.router = source["router"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IPAddress {
+	    ip: string;
+	    mask: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IPAddress(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ip = source["ip"];
+	        this.mask = source["mask"];
+	    }
+	}
+	export class LogicInterface {
+	    id: string;
+	    name: string;
+	    device: string;
+	    ip: string;
+	    mask: string;
+	    gateway: string;
+	    order: number;
+	    enabled: boolean;
+	    method: string;
+	    dns: string[];
+	    searchDomains: string[];
+	    aliases: IPAddress[];
+	    ipv6: IPv6Config;
+	    routes: Route[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LogicInterface(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.device = source["device"];
+	        this.ip = source["ip"];
+	        this.mask = source["mask"];
+	        this.gateway = source["gateway"];
+	        this.order = source["order"];
+	        this.enabled = source["enabled"];
+	        this.method = source["method"];
+	        this.dns = source["dns"];
+	        this.searchDomains = source["searchDomains"];
+	        this.aliases = this.convertValues(source["aliases"], IPAddress);
+	        this.ipv6 = this.convertValues(source["ipv6"], IPv6Config);
+	        this.routes = this.convertValues(source["routes"], Route);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InterfaceStats {
+	    rxBytes: number;
+	    txBytes: number;
+	    rxPackets: number;
+	    txPackets: number;
+	    rxErrors: number;
+	    txErrors: number;
+	    rxDropped: number;
+	    txDropped: number;
+	    rxBps: number;
+	    txBps: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new InterfaceStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rxBytes = source["rxBytes"];
+	        this.txBytes = source["txBytes"];
+	        this.rxPackets = source["rxPackets"];
+	        this.txPackets = source["txPackets"];
+	        this.rxErrors = source["rxErrors"];
+	        this.txErrors = source["txErrors"];
+	        this.rxDropped = source["rxDropped"];
+	        this.txDropped = source["txDropped"];
+	        this.rxBps = source["rxBps"];
+	        this.txBps = source["txBps"];
+	    }
+	}
+	export class LinkInfo {
+	    mtu: number;
+	    speed: number;
+	    duplex: string;
+	    media: string;
+	    carrier: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LinkInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mtu = source["mtu"];
+	        this.speed = source["speed"];
+	        this.duplex = source["duplex"];
+	        this.media = source["media"];
+	        this.carrier = source["carrier"];
+	    }
+	}
+	export class HardwareInterface {
+	    name: string;
+	    device: string;
+	    mac: string;
+	    isActive: boolean;
+	    enabled: boolean;
+	    parent: string;
+	    vlanId: number;
+	    link: LinkInfo;
+	    stats: InterfaceStats;
+	    logicInterfaces: LogicInterface[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HardwareInterface(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.device = source["device"];
+	        this.mac = source["mac"];
+	        this.isActive = source["isActive"];
+	        this.enabled = source["enabled"];
+	        this.parent = source["parent"];
+	        this.vlanId = source["vlanId"];
+	        this.link = this.convertValues(source["link"], LinkInfo);
+	        this.stats = this.convertValues(source["stats"], InterfaceStats);
+	        this.logicInterfaces = this.convertValues(source["logicInterfaces"], LogicInterface);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class IPv6Update {
+	    method: string;
+	    ip: string;
+	    prefixLength: number;
+	    router: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IPv6Update(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.method = source["method"];
+	        this.ip = source["ip"];
+	        this.prefixLength = source["prefixLength"];
+	        this.router = source["router"];
+	    }
+	}
+	
+	
+	
+	export class PendingChange {
+	    description: string;
+	    // Go type: time
+	    deadline: any;
+	    pingTarget: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PendingChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.description = source["description"];
+	        this.deadline = this.convertValues(source["deadline"], null);
+	        this.pingTarget = source["pingTarget"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProfileService {
+	    hardwarePort: string;
+	    name: string;
+	    method: string;
+	    ip: string;
+	    mask: string;
+	    gateway: string;
+	    dns: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileService(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hardwarePort = source["hardwarePort"];
+	        this.name = source["name"];
+	        this.method = source["method"];
+	        this.ip = source["ip"];
+	        this.mask = source["mask"];
+	        this.gateway = source["gateway"];
+	        this.dns = source["dns"];
+	    }
+	}
+	export class Profile {
+	    name: string;
+	    services: ProfileService[];
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.services = this.convertValues(source["services"], ProfileService);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProfileChange {
+	    action: string;
+	    hardwarePort: string;
+	    service: string;
+	    fields: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.hardwarePort = source["hardwarePort"];
+	        this.service = source["service"];
+	        this.fields = source["fields"];
+	    }
+	}
+	
+	
+	export class UpdatePayload {
+	    oldName: string;
+	    newName: string;
+	    method: string;
+	    ip: string;
+	    mask: string;
+	    gateway: string;
+	    dns: string[];
+	    searchDomains: string[];
+	    ipv6?: IPv6Update;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdatePayload(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.oldName = source["oldName"];
+	        this.newName = source["newName"];
+	        this.method = source["method"];
+	        this.ip = source["ip"];
+	        this.mask = source["mask"];
+	        this.gateway = source["gateway"];
+	        this.dns = source["dns"];
+	        this.searchDomains = source["searchDomains"];
+	        this.ipv6 = this.convertValues(source["ipv6"], IPv6Update);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class VLAN {
+	    name: string;
+	    device: string;
+	    parent: string;
+	    id: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VLAN(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.device = source["device"];
+	        this.parent = source["parent"];
+	        this.id = source["id"];
+	    }
+	}
+
+}
+
+export namespace scan {
+	
+	export class Request {
+	    service: string;
+	    cidr: string;
+	    ports: number[];
+	    reverseDns: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Request(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.service = source["service"];
+	        this.cidr = source["cidr"];
+	        this.ports = source["ports"];
+	        this.reverseDns = source["reverseDns"];
+	    }
+	}
+
+}
+
+export namespace services {
+	
+	export class ReleaseAsset {
+	    name: string;
+	    browser_download_url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReleaseAsset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.browser_download_url = source["browser_download_url"];
+	    }
+	}
+	export class ReleaseInfo {
+	    tag_name: string;
+	    body: string;
+	    assets: ReleaseAsset[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReleaseInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag_name = source["tag_name"];
+	        this.body = source["body"];
+	        this.assets = this.convertValues(source["assets"], ReleaseAsset);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace settings {
+	
+	export class PingSettings {
+	    target: string;
+	    count: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new LogicInterface(source);
+	        return new PingSettings(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.device = source["device"];
-	        this.ip = source["ip"];
-	        this.mask = source["mask"];
-	        this.gateway = source["gateway"];
-	        this.method = source["method"];
+	        this.target = source["target"];
+	        this.count = source["count"];
 	    }
 	}
-	export class HardwareInterface {
-	    name: string;
-	    device: string;
-	    mac: string;
-	    isActive: boolean;
-	    logicInterfaces: LogicInterface[];
+	export class UpdateSettings {
+	    checkOnStartup: boolean;
+	    skippedVersion: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new HardwareInterface(source);
+	        return new UpdateSettings(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.device = source["device"];
-	        this.mac = source["mac"];
-	        this.isActive = source["isActive"];
-	        this.logicInterfaces = this.convertValues(source["logicInterfaces"], LogicInterface);
+	        this.checkOnStartup = source["checkOnStartup"];
+	        this.skippedVersion = source["skippedVersion"];
+	    }
+	}
+	export class Settings {
+	    version: number;
+	    watcher: watcher.WatcherConfig;
+	    history: watcher.HistoryLimits;
+	    ping: PingSettings;
+	    update: UpdateSettings;
+	    dhcpServer: dhcp.ServerConfig;
+	    mavlinkDiscovery: mavlink.DiscoveryConfig;
+	    profiles: network.Profile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.watcher = this.convertValues(source["watcher"], watcher.WatcherConfig);
+	        this.history = this.convertValues(source["history"], watcher.HistoryLimits);
+	        this.ping = this.convertValues(source["ping"], PingSettings);
+	        this.update = this.convertValues(source["update"], UpdateSettings);
+	        this.dhcpServer = this.convertValues(source["dhcpServer"], dhcp.ServerConfig);
+	        this.mavlinkDiscovery = this.convertValues(source["mavlinkDiscovery"], mavlink.DiscoveryConfig);
+	        this.profiles = this.convertValues(source["profiles"], network.Profile);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -62,62 +1174,91 @@ export namespace network {
 		    return a;
 		}
 	}
+
+}
+
+export namespace watcher {
 	
-	export class UpdatePayload {
-	    oldName: string;
-	    newName: string;
-	    method: string;
-	    ip: string;
-	    mask: string;
-	    gateway: string;
+	export class ConnectionState {
+	    state: string;
+	    remote: string;
+	    attempt: number;
+	    retryIn: number;
+	    error: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new UpdatePayload(source);
+	        return new ConnectionState(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.oldName = source["oldName"];
-	        this.newName = source["newName"];
-	        this.method = source["method"];
-	        this.ip = source["ip"];
-	        this.mask = source["mask"];
-	        this.gateway = source["gateway"];
+	        this.state = source["state"];
+	        this.remote = source["remote"];
+	        this.attempt = source["attempt"];
+	        this.retryIn = source["retryIn"];
+	        this.error = source["error"];
 	    }
 	}
-
-}
-
-export namespace services {
-	
-	export class ReleaseAsset {
-	    name: string;
-	    browser_download_url: string;
+	export class PacketQuery {
+	    fromId: number;
+	    toId: number;
+	    // Go type: time
+	    since: any;
+	    // Go type: time
+	    until: any;
+	    source: string;
+	    filter: string;
+	    offset: number;
+	    limit: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ReleaseAsset(source);
+	        return new PacketQuery(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.browser_download_url = source["browser_download_url"];
+	        this.fromId = source["fromId"];
+	        this.toId = source["toId"];
+	        this.since = this.convertValues(source["since"], null);
+	        this.until = this.convertValues(source["until"], null);
+	        this.source = source["source"];
+	        this.filter = source["filter"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class ReleaseInfo {
-	    tag_name: string;
-	    body: string;
-	    assets: ReleaseAsset[];
+	export class ExportRequest {
+	    format: string;
+	    path: string;
+	    query: PacketQuery;
 	
 	    static createFrom(source: any = {}) {
-	        return new ReleaseInfo(source);
+	        return new ExportRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.tag_name = source["tag_name"];
-	        this.body = source["body"];
-	        this.assets = this.convertValues(source["assets"], ReleaseAsset);
+	        this.format = source["format"];
+	        this.path = source["path"];
+	        this.query = this.convertValues(source["query"], PacketQuery);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -138,26 +1279,75 @@ export namespace services {
 		    return a;
 		}
 	}
-
-}
-
-export namespace watcher {
+	export class ExportResult {
+	    path: string;
+	    count: number;
+	    error: string;
 	
-	export class ParserMeta {
-	    id: string;
-	    name: string;
-	    description: string;
+	    static createFrom(source: any = {}) {
+	        return new ExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.count = source["count"];
+	        this.error = source["error"];
+	    }
+	}
+	export class HistoryLimits {
+	    maxPackets: number;
+	    maxBytes: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ParserMeta(source);
+	        return new HistoryLimits(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.description = source["description"];
+	        this.maxPackets = source["maxPackets"];
+	        this.maxBytes = source["maxBytes"];
+	    }
+	}
+	export class HistoryStats {
+	    limits: HistoryLimits;
+	    count: number;
+	    bytes: number;
+	    oldestId: number;
+	    newestId: number;
+	    dropped: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryStats(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.limits = this.convertValues(source["limits"], HistoryLimits);
+	        this.count = source["count"];
+	        this.bytes = source["bytes"];
+	        this.oldestId = source["oldestId"];
+	        this.newestId = source["newestId"];
+	        this.dropped = source["dropped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class UDPPacket {
 	    id: number;
@@ -206,10 +1396,70 @@ export namespace watcher {
 		    return a;
 		}
 	}
+	export class PacketPage {
+	    packets: UDPPacket[];
+	    total: number;
+	    offset: number;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PacketPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.packets = this.convertValues(source["packets"], UDPPacket);
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ParserMeta {
+	    id: string;
+	    name: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ParserMeta(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	    }
+	}
+	
 	export class WatcherConfig {
 	    protocol: string;
 	    port: number;
 	    parser: string;
+	    remoteHost: string;
+	    bindAddress: string;
+	    ipVersion: string;
+	    multicastGroup: string;
+	    multicastIface: string;
+	    reusePort: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new WatcherConfig(source);
@@ -220,11 +1470,18 @@ export namespace watcher {
 	        this.protocol = source["protocol"];
 	        this.port = source["port"];
 	        this.parser = source["parser"];
+	        this.remoteHost = source["remoteHost"];
+	        this.bindAddress = source["bindAddress"];
+	        this.ipVersion = source["ipVersion"];
+	        this.multicastGroup = source["multicastGroup"];
+	        this.multicastIface = source["multicastIface"];
+	        this.reusePort = source["reusePort"];
 	    }
 	}
 	export class WatcherState {
 	    config: WatcherConfig;
 	    running: boolean;
+	    connection: ConnectionState;
 	
 	    static createFrom(source: any = {}) {
 	        return new WatcherState(source);
@@ -234,6 +1491,82 @@ export namespace watcher {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.config = this.convertValues(source["config"], WatcherConfig);
 	        this.running = source["running"];
+	        this.connection = this.convertValues(source["connection"], ConnectionState);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace wifi {
+	
+	export class JoinRequest {
+	    ssid: string;
+	    password: string;
+	    hidden: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new JoinRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ssid = source["ssid"];
+	        this.password = source["password"];
+	        this.hidden = source["hidden"];
+	    }
+	}
+	export class Network {
+	    ssid: string;
+	    bssid: string;
+	    channel: number;
+	    frequency: number;
+	    rssi: number;
+	    security: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Network(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ssid = source["ssid"];
+	        this.bssid = source["bssid"];
+	        this.channel = source["channel"];
+	        this.frequency = source["frequency"];
+	        this.rssi = source["rssi"];
+	        this.security = source["security"];
+	    }
+	}
+	export class ScanResult {
+	    networks: Network[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.networks = this.convertValues(source["networks"], Network);
+	        this.error = source["error"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -254,6 +1587,38 @@ export namespace watcher {
 		    return a;
 		}
 	}
+	export class Status {
+	    interface: string;
+	    poweredOn: boolean;
+	    connected: boolean;
+	    ssid: string;
+	    bssid: string;
+	    channel: number;
+	    rssi: number;
+	    noise: number;
+	    txRate: number;
+	    security: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.interface = source["interface"];
+	        this.poweredOn = source["poweredOn"];
+	        this.connected = source["connected"];
+	        this.ssid = source["ssid"];
+	        this.bssid = source["bssid"];
+	        this.channel = source["channel"];
+	        this.rssi = source["rssi"];
+	        this.noise = source["noise"];
+	        this.txRate = source["txRate"];
+	        this.security = source["security"];
+	        this.error = source["error"];
+	    }
+	}
 
 }
 
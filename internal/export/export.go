@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"macbox/pkg/watcher"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatHexdump = "hexdump"
+)
+
+// Extension returns the usual file extension for a format.
+func Extension(format string) string {
+	switch format {
+	case FormatCSV:
+		return ".csv"
+	case FormatNDJSON:
+		return ".jsonl"
+	default:
+		return ".txt"
+	}
+}
+
+// CheckFormat reports an error for a format Write does not know.
+func CheckFormat(format string) error {
+	switch format {
+	case FormatCSV, FormatNDJSON, FormatHexdump:
+		return nil
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// Write encodes packets to w and returns how many were written.
+func Write(w io.Writer, format string, packets []watcher.UDPPacket) (int, error) {
+	if err := CheckFormat(format); err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(w)
+
+	var (
+		count int
+		err   error
+	)
+	switch format {
+	case FormatCSV:
+		count, err = writeCSV(bw, packets)
+	case FormatNDJSON:
+		count, err = writeNDJSON(bw, packets)
+	case FormatHexdump:
+		count, err = writeHexdump(bw, packets)
+	}
+	if err != nil {
+		return count, err
+	}
+
+	return count, bw.Flush()
+}
+
+var baseColumns = []string{"id", "timestamp", "protocol", "parser", "from_ip", "port", "size", "payload_hex"}
+
+// writeCSV emits one row per packet. ParsedData is flattened to dotted
+// columns ("payload.Lat", "summary.State") collected over all packets, so
+// packets of different message types share one header.
+func writeCSV(w io.Writer, packets []watcher.UDPPacket) (int, error) {
+	keySet := make(map[string]struct{})
+	for _, p := range packets {
+		for k := range flatten(p.ParsedData) {
+			keySet[k] = struct{}{}
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(append([]string{}, baseColumns...), keys...)); err != nil {
+		return 0, err
+	}
+
+	row := make([]string, len(baseColumns)+len(keys))
+	for count, p := range packets {
+		row[0] = strconv.FormatInt(p.ID, 10)
+		row[1] = p.Timestamp.Format(time.RFC3339Nano)
+		row[2] = p.Protocol
+		row[3] = p.Parser
+		row[4] = p.FromIP
+		row[5] = strconv.Itoa(p.Port)
+		row[6] = strconv.Itoa(p.Size)
+		row[7] = hex.EncodeToString(p.Payload)
+
+		flat := flatten(p.ParsedData)
+		for i, k := range keys {
+			row[len(baseColumns)+i] = flat[k]
+		}
+
+		if err := cw.Write(row); err != nil {
+			return count, err
+		}
+	}
+
+	cw.Flush()
+	return len(packets), cw.Error()
+}
+
+func writeNDJSON(w io.Writer, packets []watcher.UDPPacket) (int, error) {
+	enc := json.NewEncoder(w)
+
+	for count, p := range packets {
+		if err := enc.Encode(p); err != nil {
+			return count, err
+		}
+	}
+	return len(packets), nil
+}
+
+// writeHexdump produces the classic `hexdump -C` layout, one block per packet.
+func writeHexdump(w io.Writer, packets []watcher.UDPPacket) (int, error) {
+	for count, p := range packets {
+		_, err := fmt.Fprintf(w, "# packet %d  %s  %s %s  %d bytes  %s\n",
+			p.ID, p.Timestamp.Format("2006-01-02 15:04:05.000"), p.Protocol, p.FromIP, p.Size, p.Parser)
+		if err != nil {
+			return count, err
+		}
+		if _, err := io.WriteString(w, hex.Dump(p.Payload)); err != nil {
+			return count, err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return count, err
+		}
+	}
+	return len(packets), nil
+}
+
+func flatten(data map[string]any) map[string]string {
+	out := make(map[string]string)
+	flattenInto(out, "", data)
+	return out
+}
+
+func flattenInto(out map[string]string, prefix string, data map[string]any) {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		switch val := v.(type) {
+		case map[string]any:
+			flattenInto(out, key, val)
+		case nil:
+			out[key] = ""
+		case string:
+			out[key] = val
+		case []any:
+			b, _ := json.Marshal(val)
+			out[key] = string(b)
+		default:
+			out[key] = fmt.Sprint(val)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package filter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"macbox/pkg/watcher"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter reports whether a packet matches a compiled expression.
+type Filter func(p watcher.UDPPacket) bool
+
+// Compile parses a filter expression.
+//
+// Examples:
+//
+//	name == HEARTBEAT && system_id == 1
+//	size > 100 || from ~= 192.168.1.
+//	!(parser == raw) && payload.Lat >= 50.4
+//	hex ~= "fe09"
+//
+// Known fields are id, size, port, from, protocol, parser, hex and text.
+// Any other name is looked up in ParsedData, dots walk nested maps.
+// An empty expression matches everything.
+func Compile(expr string) (Filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return func(watcher.UDPPacket) bool { return true }, nil
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokOp
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+var operators = []string{"==", "!=", ">=", "<=", "~=", ">", "<"}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+
+	for i < len(expr) {
+		c := expr[i]
+
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, token{tokAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{tokOr, "||", i})
+			i += 2
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{tokString, expr[i+1 : i+1+end], i})
+			i += end + 2
+		default:
+			if op := matchOperator(expr[i:]); op != "" {
+				tokens = append(tokens, token{tokOp, op, i})
+				i += len(op)
+				continue
+			}
+			if c == '!' {
+				tokens = append(tokens, token{tokNot, "!", i})
+				i++
+				continue
+			}
+
+			start := i
+			for i < len(expr) && !isDelimiter(expr[i:]) {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, expr[start:i], start})
+		}
+	}
+
+	return tokens, nil
+}
+
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func isDelimiter(s string) bool {
+	c := s[0]
+	if unicode.IsSpace(rune(c)) || c == '(' || c == ')' || c == '"' || c == '\'' || c == '!' {
+		return true
+	}
+	return strings.HasPrefix(s, "&&") || strings.HasPrefix(s, "||") || matchOperator(s) != ""
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t != nil && t.kind == tokOr; t = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(pk watcher.UDPPacket) bool { return l(pk) || right(pk) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t != nil && t.kind == tokAnd; t = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(pk watcher.UDPPacket) bool { return l(pk) && right(pk) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch t.kind {
+	case tokNot:
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(pk watcher.UDPPacket) bool { return !inner(pk) }, nil
+
+	case tokLParen:
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return inner, nil
+
+	case tokIdent:
+		return p.parseCondition()
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.offset)
+}
+
+func (p *parser) parseCondition() (Filter, error) {
+	field := p.tokens[p.pos].text
+	p.pos++
+
+	op := p.peek()
+	if op == nil || op.kind != tokOp {
+		// Bare field: matches when the field is present and not empty.
+		return func(pk watcher.UDPPacket) bool {
+			v, ok := lookup(pk, field)
+			return ok && v != "" && v != "false" && v != "0"
+		}, nil
+	}
+	p.pos++
+
+	val := p.peek()
+	if val == nil || (val.kind != tokIdent && val.kind != tokString) {
+		return nil, fmt.Errorf("missing value after %q", op.text)
+	}
+	p.pos++
+
+	return comparison(field, op.text, val.text), nil
+}
+
+func comparison(field, op, want string) Filter {
+	wantNum, wantIsNum := parseNumber(want)
+	wantLower := strings.ToLower(want)
+
+	return func(pk watcher.UDPPacket) bool {
+		got, ok := lookup(pk, field)
+		if !ok {
+			return op == "!="
+		}
+
+		if op == "~=" {
+			return strings.Contains(strings.ToLower(got), wantLower)
+		}
+
+		var cmp int
+		if gotNum, gotIsNum := parseNumber(got); gotIsNum && wantIsNum {
+			switch {
+			case gotNum < wantNum:
+				cmp = -1
+			case gotNum > wantNum:
+				cmp = 1
+			}
+		} else {
+			cmp = strings.Compare(strings.ToLower(got), wantLower)
+		}
+
+		switch op {
+		case "==":
+			return cmp == 0
+		case "!=":
+			return cmp != 0
+		case ">":
+			return cmp > 0
+		case ">=":
+			return cmp >= 0
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		}
+		return false
+	}
+}
+
+func parseNumber(s string) (float64, bool) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		n, err := strconv.ParseInt(s[2:], 16, 64)
+		return float64(n), err == nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
+
+func lookup(pk watcher.UDPPacket, field string) (string, bool) {
+	switch strings.ToLower(field) {
+	case "id":
+		return strconv.FormatInt(pk.ID, 10), true
+	case "size":
+		return strconv.Itoa(pk.Size), true
+	case "port":
+		return strconv.Itoa(pk.Port), true
+	case "from":
+		return pk.FromIP, true
+	case "protocol":
+		return pk.Protocol, true
+	case "parser":
+		return pk.Parser, true
+	case "hex":
+		return hex.EncodeToString(pk.Payload), true
+	case "text":
+		return string(pk.Payload), true
+	}
+
+	path := strings.TrimPrefix(field, "parsed.")
+	var cur any = pk.ParsedData
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if cur, ok = m[key]; !ok {
+			return "", false
+		}
+	}
+
+	if cur == nil {
+		return "", false
+	}
+	return fmt.Sprint(cur), true
+}
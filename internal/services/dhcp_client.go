@@ -0,0 +1,205 @@
+//go:build darwin || linux
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"macbox/pkg/dhcp"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+)
+
+// dhcpProbeTimeout is how long DiscoverDHCPServers collects offers.
+const dhcpProbeTimeout = 3 * time.Second
+
+// GetDHCPLease returns the lease the system DHCP client holds for a
+// service whose method is DHCP.
+func (ns *NetworkService) GetDHCPLease(serviceName string) dhcp.ClientLease {
+	lease := dhcp.ClientLease{Service: serviceName, DNS: []string{}, Options: []dhcp.Option{}}
+
+	device, errMsg := ns.dhcpDevice(serviceName)
+	if errMsg != "" {
+		lease.Error = errMsg
+		return lease
+	}
+
+	found, err := readDHCPLease(device)
+	if err != nil {
+		lease.Device = device
+		lease.Error = err.Error()
+		return lease
+	}
+	found.Service = serviceName
+	return found
+}
+
+// RenewDHCPLease asks the system DHCP client to contact the server again.
+func (ns *NetworkService) RenewDHCPLease(serviceName string) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	device, errMsg := ns.dhcpDevice(serviceName)
+	if errMsg != "" {
+		return errMsg
+	}
+	return errString(renewDHCPLease(device))
+}
+
+// ReleaseDHCPLease gives the address back to the server. The service
+// stays without an IPv4 address until RenewDHCPLease.
+func (ns *NetworkService) ReleaseDHCPLease(serviceName string) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	device, errMsg := ns.dhcpDevice(serviceName)
+	if errMsg != "" {
+		return errMsg
+	}
+	return errString(releaseDHCPLease(device))
+}
+
+func (ns *NetworkService) dhcpDevice(serviceName string) (string, string) {
+	for _, hw := range ns.interfaces() {
+		for _, li := range hw.LogicInterfaces {
+			if li.Name != serviceName {
+				continue
+			}
+			if li.Method != "DHCP" {
+				return "", fmt.Sprintf("%s does not use DHCP.", serviceName)
+			}
+			return hw.Device, ""
+		}
+	}
+	return "", "Service or Device not found. It might have been deleted."
+}
+
+// DiscoverDHCPServers broadcasts a DISCOVER on device and lists every
+// server that answers within dhcpProbeTimeout. No address is requested,
+// the offers simply time out on the servers.
+func (ns *NetworkService) DiscoverDHCPServers(device string) dhcp.DiscoverResult {
+	result := dhcp.DiscoverResult{Device: device, Offers: []dhcp.Offer{}}
+	if !ns.hasDevice(device) {
+		result.Error = "Service or Device not found. It might have been deleted."
+		return result
+	}
+
+	offers, err := probeDHCPServers(device, dhcpProbeTimeout)
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if current, err := readDHCPLease(device); err == nil && current.Server != "" {
+		for i := range offers {
+			offers[i].Current = offers[i].Server == current.Server
+		}
+	}
+	result.Offers = append(result.Offers, offers...)
+	return result
+}
+
+func probeDHCPServers(device string, timeout time.Duration) ([]dhcp.Offer, error) {
+	ifi, err := net.InterfaceByName(device)
+	if err != nil {
+		return nil, err
+	}
+	if len(ifi.HardwareAddr) != 6 {
+		return nil, fmt.Errorf("%s has no Ethernet address, DHCP does not run on it", device)
+	}
+
+	conn, err := openDHCPProbeConn(device)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot open a DHCP client socket on %s: %v", device, err)
+	}
+	defer conn.Close()
+
+	// The broadcast flag makes servers broadcast the offer, we have no
+	// address they could unicast it to.
+	discover, err := dhcpv4.NewDiscovery(ifi.HardwareAddr, dhcpv4.WithBroadcast(true))
+	if err != nil {
+		return nil, err
+	}
+	dst := &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ServerPort}
+	if _, err := conn.WriteTo(discover.ToBytes(), dst); err != nil {
+		return nil, err
+	}
+
+	offers := []dhcp.Offer{}
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return offers, err
+	}
+	buffer := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buffer)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return offers, nil
+		}
+		if err != nil {
+			return offers, err
+		}
+
+		msg, err := dhcpv4.FromBytes(buffer[:n])
+		if err != nil || msg.TransactionID != discover.TransactionID || msg.MessageType() != dhcpv4.MessageTypeOffer {
+			continue
+		}
+		offer := offerFromMessage(msg, from)
+		if !slices.ContainsFunc(offers, func(o dhcp.Offer) bool { return o.Server == offer.Server && o.From == offer.From }) {
+			offers = append(offers, offer)
+		}
+	}
+}
+
+func offerFromMessage(msg *dhcpv4.DHCPv4, from net.Addr) dhcp.Offer {
+	offer := dhcp.Offer{
+		OfferedIP: msg.YourIPAddr.String(),
+		DNS:       ipStrings(msg.DNS()),
+		LeaseTime: int(msg.IPAddressLeaseTime(0) / time.Second),
+		Options:   messageOptions(msg),
+	}
+	if udp, ok := from.(*net.UDPAddr); ok {
+		offer.From = udp.IP.String()
+	}
+	if sid := msg.ServerIdentifier(); sid != nil {
+		offer.Server = sid.String()
+	} else {
+		offer.Server = offer.From
+	}
+	if mask := msg.SubnetMask(); mask != nil {
+		offer.SubnetMask = net.IP(mask).String()
+	}
+	if routers := msg.Router(); len(routers) > 0 {
+		offer.Router = routers[0].String()
+	}
+	return offer
+}
+
+// messageOptions lists the options of msg by code with the names and
+// value formatting of the dhcpv4 package.
+func messageOptions(msg *dhcpv4.DHCPv4) []dhcp.Option {
+	codes := make([]int, 0, len(msg.Options))
+	for code := range msg.Options {
+		codes = append(codes, int(code))
+	}
+	slices.Sort(codes)
+
+	options := make([]dhcp.Option, 0, len(codes))
+	for _, code := range codes {
+		single := dhcpv4.Options{uint8(code): msg.Options[uint8(code)]}
+		name, value, _ := strings.Cut(strings.TrimSpace(single.String()), ": ")
+		options = append(options, dhcp.Option{Code: code, Name: name, Value: value})
+	}
+	return options
+}
+
+func ipStrings(ips []net.IP) []string {
+	out := make([]string, len(ips))
+	for i, ip := range ips {
+		out[i] = ip.String()
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"macbox/pkg/dhcp"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/nclient4"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// dhclientLeaseFiles are where dhclient keeps its leases, directly and
+// when run by NetworkManager or ifupdown.
+var dhclientLeaseFiles = []string{
+	"/var/lib/dhcp/dhclient*.leases",
+	"/var/lib/dhclient/dhclient*.leases",
+	"/var/lib/NetworkManager/dhclient-*.lease",
+}
+
+// infinityLifetime is the valid_lft of an address that never expires.
+const infinityLifetime = 0xFFFFFFFF
+
+// readDHCPLease prefers the dhclient lease of the address currently on
+// the link. Other clients (systemd-networkd, NetworkManager's internal
+// one) keep their leases private, then only what the kernel knows is
+// shown: the address and its remaining lifetime.
+func readDHCPLease(device string) (dhcp.ClientLease, error) {
+	link, err := netlink.LinkByName(device)
+	if err != nil {
+		return dhcp.ClientLease{}, errors.New(parseLinkError(err))
+	}
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return dhcp.ClientLease{}, err
+	}
+
+	onLink := map[string]bool{}
+	for _, a := range addrs {
+		onLink[a.IP.String()] = true
+	}
+	for _, pattern := range dhclientLeaseFiles {
+		files, _ := filepath.Glob(pattern)
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			leases := parseDhclientLeases(string(data), device)
+			// The newest lease is the last one in the file.
+			for i := len(leases) - 1; i >= 0; i-- {
+				if onLink[leases[i].IP] {
+					return leases[i], nil
+				}
+			}
+		}
+	}
+
+	for _, a := range addrs {
+		if a.Flags&unix.IFA_F_PERMANENT != 0 {
+			continue
+		}
+		lease := dhcp.ClientLease{
+			Device:     device,
+			Source:     "kernel",
+			IP:         a.IP.String(),
+			SubnetMask: net.IP(a.Mask).String(),
+			Options:    []dhcp.Option{},
+		}
+		if a.ValidLft > 0 && a.ValidLft < infinityLifetime {
+			lease.Expires = time.Now().Add(time.Duration(a.ValidLft) * time.Second).Truncate(time.Second)
+		}
+		lease.DNS, _ = getLinkDNS(device)
+		routes, _ := netlink.RouteList(link, netlink.FAMILY_V4)
+		if gw := defaultGateway(link.Attrs().Index, routes); gw != nil {
+			lease.Router = gw.String()
+		}
+		return lease, nil
+	}
+	return dhcp.ClientLease{}, fmt.Errorf("No DHCP lease found on %s.", device)
+}
+
+// parseDhclientLeases reads the lease blocks of device from a dhclient
+// lease file:
+//
+//	lease {
+//	  interface "eth0";
+//	  fixed-address 192.168.1.23;
+//	  option subnet-mask 255.255.255.0;
+//	  option dhcp-lease-time 86400;
+//	  expire 2 2026/10/20 13:00:00;
+//	}
+func parseDhclientLeases(data, device string) []dhcp.ClientLease {
+	var leases []dhcp.ClientLease
+	var current *dhcp.ClientLease
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "lease {":
+			current = &dhcp.ClientLease{Source: "dhclient", DNS: []string{}, Options: []dhcp.Option{}}
+			continue
+		case current == nil:
+			continue
+		case line == "}":
+			if current.Device == device {
+				leases = append(leases, *current)
+			}
+			current = nil
+			continue
+		}
+
+		line = strings.TrimSuffix(line, ";")
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "interface":
+			current.Device = strings.Trim(value, `"`)
+		case "fixed-address":
+			current.IP = value
+		case "expire":
+			current.Expires = parseDhclientTime(value)
+		case "option":
+			name, value, _ := strings.Cut(value, " ")
+			value = strings.Trim(value, `"`)
+			applyDhclientOption(current, name, value)
+		}
+	}
+	return leases
+}
+
+func applyDhclientOption(lease *dhcp.ClientLease, name, value string) {
+	option := dhcp.Option{Name: name, Value: value}
+	if code, ok := strings.CutPrefix(name, "unknown-"); ok {
+		option.Code, _ = strconv.Atoi(code)
+	}
+	lease.Options = append(lease.Options, option)
+
+	switch name {
+	case "subnet-mask":
+		lease.SubnetMask = value
+	case "routers":
+		lease.Router, _, _ = strings.Cut(value, ",")
+	case "domain-name-servers":
+		lease.DNS = strings.Split(value, ",")
+	case "domain-name":
+		lease.DomainName = value
+	case "dhcp-server-identifier":
+		lease.Server = value
+	case "dhcp-lease-time":
+		lease.LeaseTime, _ = strconv.Atoi(value)
+	}
+}
+
+// parseDhclientTime reads "2 2026/10/20 13:00:00" (weekday, UTC) or
+// "epoch 1792501200; # Tue Oct 20 ..." into a time, zero for "never".
+func parseDhclientTime(value string) time.Time {
+	fields := strings.Fields(value)
+	if len(fields) >= 2 && fields[0] == "epoch" {
+		sec, err := strconv.ParseInt(strings.TrimSuffix(fields[1], ";"), 10, 64)
+		if err == nil {
+			return time.Unix(sec, 0)
+		}
+	}
+	if len(fields) >= 3 {
+		t, err := time.Parse("2006/01/02 15:04:05", fields[1]+" "+fields[2])
+		if err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// renewDHCPLease restarts dhclient on device. The new instance asks for
+// the address it had before (INIT-REBOOT), which the server confirms.
+func renewDHCPLease(device string) error {
+	_ = runCommand("dhclient", "-x", device)
+	return runCommand("dhclient", "-1", device)
+}
+
+func releaseDHCPLease(device string) error {
+	return runCommand("dhclient", "-r", device)
+}
+
+// openDHCPProbeConn uses a packet socket, port 68 usually belongs to the
+// running DHCP client and the link may have no address to send from.
+func openDHCPProbeConn(device string) (net.PacketConn, error) {
+	return nclient4.NewRawUDPConn(device, dhcpv4.ClientPort)
+}
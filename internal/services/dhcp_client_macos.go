@@ -0,0 +1,152 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"macbox/pkg/dhcp"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+)
+
+// ipconfigOption matches an option line of `ipconfig getpacket`:
+//
+//	domain_name_server (ip_mult): {192.168.1.1, 8.8.8.8}
+var ipconfigOption = regexp.MustCompile(`^(\w+) \((\w+)\): ?(.*)$`)
+
+// ipconfigOptionCodes are the codes of the options ipconfig prints by name.
+var ipconfigOptionCodes = map[string]int{
+	"subnet_mask":            1,
+	"router":                 3,
+	"domain_name_server":     6,
+	"host_name":              12,
+	"domain_name":            15,
+	"broadcast_address":      28,
+	"ntp_servers":            42,
+	"lease_time":             51,
+	"dhcp_message_type":      53,
+	"server_identifier":      54,
+	"renewal_t1_time":        58,
+	"rebinding_t2_time":      59,
+	"domain_search":          119,
+	"classless_static_route": 121,
+}
+
+// readDHCPLease reads the last packet configd's DHCP client accepted on
+// device. The lease start comes from `ipconfig getsummary`.
+func readDHCPLease(device string) (dhcp.ClientLease, error) {
+	out, err := exec.Command("ipconfig", "getpacket", device).Output()
+	if err != nil || len(strings.TrimSpace(string(out))) == 0 {
+		return dhcp.ClientLease{}, fmt.Errorf("No DHCP lease found on %s.", device)
+	}
+
+	lease := parseIPConfigPacket(string(out))
+	lease.Device = device
+	if start := ipconfigLeaseStart(device); !start.IsZero() && lease.LeaseTime > 0 {
+		lease.Expires = start.Add(time.Duration(lease.LeaseTime) * time.Second)
+	}
+	return lease, nil
+}
+
+func parseIPConfigPacket(output string) dhcp.ClientLease {
+	lease := dhcp.ClientLease{Source: "ipconfig", DNS: []string{}, Options: []dhcp.Option{}}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if ip, ok := strings.CutPrefix(line, "yiaddr = "); ok {
+			lease.IP = ip
+			continue
+		}
+
+		m := ipconfigOption.FindStringSubmatch(line)
+		if m == nil || m[1] == "end" {
+			continue
+		}
+		name, kind, value := m[1], m[2], m[3]
+		values := []string{value}
+		if kind == "ip_mult" || strings.HasPrefix(value, "{") {
+			values = strings.Split(strings.Trim(value, "{}"), ",")
+			for i := range values {
+				values[i] = strings.TrimSpace(values[i])
+			}
+		}
+		lease.Options = append(lease.Options, dhcp.Option{Code: ipconfigOptionCodes[name], Name: name, Value: value})
+
+		switch name {
+		case "subnet_mask":
+			lease.SubnetMask = value
+		case "router":
+			lease.Router = values[0]
+		case "domain_name_server":
+			lease.DNS = values
+		case "domain_name":
+			lease.DomainName = value
+		case "server_identifier":
+			lease.Server = value
+		case "lease_time":
+			seconds, _ := strconv.ParseUint(value, 0, 32) // 0x15180
+			lease.LeaseTime = int(seconds)
+		}
+	}
+	return lease
+}
+
+// ipconfigLeaseStart finds "LeaseStartTime : <date>" in the summary,
+// zero if this macOS does not print it.
+func ipconfigLeaseStart(device string) time.Time {
+	out, err := exec.Command("ipconfig", "getsummary", device).Output()
+	if err != nil {
+		return time.Time{}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		_, value, ok := strings.Cut(scanner.Text(), "LeaseStartTime : ")
+		if !ok {
+			continue
+		}
+		for _, layout := range []string{"2006-01-02 15:04:05 -0700", "01/02/2006 15:04:05"} {
+			if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// renewDHCPLease makes configd restart DHCP on device, it asks for the
+// address it had.
+func renewDHCPLease(device string) error {
+	return ipconfig("set", device, "DHCP")
+}
+
+// releaseDHCPLease unconfigures IPv4 on device, configd drops the lease
+// until renewDHCPLease.
+func releaseDHCPLease(device string) error {
+	return ipconfig("set", device, "NONE")
+}
+
+func ipconfig(args ...string) error {
+	out, err := exec.Command("ipconfig", args...).CombinedOutput()
+	if err != nil {
+		return errors.New(parseNetworkError(out, err))
+	}
+	return nil
+}
+
+// openDHCPProbeConn binds port 68 on device with SO_REUSEPORT. macOS has
+// no packet sockets, the bind fails if another process holds the port
+// exclusively.
+func openDHCPProbeConn(device string) (net.PacketConn, error) {
+	return server4.NewIPv4UDPConn(device, &net.UDPAddr{Port: dhcpv4.ClientPort})
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"macbox/pkg/dhcp"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+const (
+	// dhcpOfferTimeout is how long an offered address is held for a
+	// client that has not requested it yet.
+	dhcpOfferTimeout = time.Minute
+	// dhcpDeclineHold keeps an address a client reported as taken
+	// (DHCPDECLINE) out of the pool.
+	dhcpDeclineHold = 10 * time.Minute
+)
+
+// leaseTable allocates addresses of the pool and keeps the leases by MAC.
+// It is not safe for concurrent use, DHCPServerService holds its lock.
+type leaseTable struct {
+	server     netip.Addr
+	start, end netip.Addr
+	leaseTime  time.Duration
+
+	reserved    map[string]dhcp.Reservation // by MAC
+	reservedIPs map[netip.Addr]bool
+	leases      map[string]*dhcp.Lease // by MAC
+	byIP        map[netip.Addr]string  // IP -> MAC
+	declined    map[netip.Addr]time.Time
+}
+
+// newLeaseTable expects a validated config.
+func newLeaseTable(cfg dhcp.ServerConfig) *leaseTable {
+	t := &leaseTable{
+		server:    netip.MustParseAddr(cfg.ServerIP),
+		start:     netip.MustParseAddr(cfg.PoolStart),
+		end:       netip.MustParseAddr(cfg.PoolEnd),
+		leaseTime: time.Duration(cfg.LeaseTime) * time.Second,
+		leases:    map[string]*dhcp.Lease{},
+		byIP:      map[netip.Addr]string{},
+		declined:  map[netip.Addr]time.Time{},
+	}
+	t.setReservations(cfg.Reservations)
+	return t
+}
+
+// setReservations replaces the reservations. Dynamic leases on a newly
+// reserved address are dropped and returned, the client gets a NAK on
+// its next renewal and starts over.
+func (t *leaseTable) setReservations(reservations []dhcp.Reservation) []dhcp.Lease {
+	t.reserved = map[string]dhcp.Reservation{}
+	t.reservedIPs = map[netip.Addr]bool{}
+	for _, r := range reservations {
+		t.reserved[r.MAC] = r
+		t.reservedIPs[netip.MustParseAddr(r.IP)] = true
+	}
+
+	var dropped []dhcp.Lease
+	for mac, l := range t.leases {
+		r, isReserved := t.reserved[mac]
+		ip := netip.MustParseAddr(l.IP)
+		switch {
+		case isReserved && r.IP == l.IP:
+			l.Static = true
+			continue
+		case !isReserved && !t.reservedIPs[ip] && t.inPool(ip):
+			l.Static = false
+			continue
+		}
+		dropped = append(dropped, *l)
+		t.remove(mac)
+	}
+	return dropped
+}
+
+func (t *leaseTable) inPool(ip netip.Addr) bool {
+	return ip.Compare(t.start) >= 0 && ip.Compare(t.end) <= 0
+}
+
+// free reports whether ip can be given to mac.
+func (t *leaseTable) free(ip netip.Addr, mac string, now time.Time) bool {
+	if ip == t.server || t.reservedIPs[ip] || !t.inPool(ip) {
+		return false
+	}
+	if until, ok := t.declined[ip]; ok && now.Before(until) {
+		return false
+	}
+	owner, ok := t.byIP[ip]
+	return !ok || owner == mac || now.After(t.leases[owner].Expires)
+}
+
+// pick chooses the address for mac: its reservation, its current lease,
+// the address it asked for, or the first free one in the pool.
+func (t *leaseTable) pick(mac string, requested netip.Addr, now time.Time) (netip.Addr, bool) {
+	if r, ok := t.reserved[mac]; ok {
+		return netip.MustParseAddr(r.IP), true
+	}
+	if l, ok := t.leases[mac]; ok {
+		return netip.MustParseAddr(l.IP), true
+	}
+	if requested.IsValid() && t.free(requested, mac, now) {
+		return requested, true
+	}
+	for ip := t.start; ip.Compare(t.end) <= 0; ip = ip.Next() {
+		if t.free(ip, mac, now) {
+			return ip, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// offer holds an address for mac, false if the pool is exhausted.
+func (t *leaseTable) offer(mac, hostname string, requested netip.Addr, now time.Time) (dhcp.Lease, bool) {
+	ip, ok := t.pick(mac, requested, now)
+	if !ok {
+		return dhcp.Lease{}, false
+	}
+
+	if l, ok := t.leases[mac]; ok && l.State == "bound" && now.Before(l.Expires) {
+		// A bound client that rediscovers keeps its lease as is.
+		return *l, true
+	}
+	return t.set(mac, hostname, ip, "offered", now.Add(dhcpOfferTimeout)), true
+}
+
+// request binds ip to mac. It fails if ip is not the one this client
+// may have, the caller then answers with a NAK. renewed tells a renewal
+// of a bound lease from a new binding.
+func (t *leaseTable) request(mac, hostname string, ip netip.Addr, now time.Time) (lease dhcp.Lease, renewed bool, ok bool) {
+	if r, isReserved := t.reserved[mac]; isReserved {
+		if r.IP != ip.String() {
+			return dhcp.Lease{}, false, false
+		}
+	} else if l, has := t.leases[mac]; has {
+		if l.IP != ip.String() {
+			return dhcp.Lease{}, false, false
+		}
+	} else if !t.free(ip, mac, now) {
+		// A client we do not know (INIT-REBOOT after a restart of the
+		// server) keeps its old address only if nobody else has it.
+		return dhcp.Lease{}, false, false
+	}
+
+	renewed = t.leases[mac] != nil && t.leases[mac].State == "bound"
+	if hostname == "" && t.leases[mac] != nil {
+		hostname = t.leases[mac].Hostname
+	}
+	return t.set(mac, hostname, ip, "bound", now.Add(t.leaseTime)), renewed, true
+}
+
+func (t *leaseTable) release(mac string) (dhcp.Lease, bool) {
+	l, ok := t.leases[mac]
+	if !ok {
+		return dhcp.Lease{}, false
+	}
+	t.remove(mac)
+	return *l, true
+}
+
+// decline drops the lease of mac and keeps its address out of the pool
+// for a while, some other host on the segment already uses it.
+func (t *leaseTable) decline(mac string, now time.Time) (dhcp.Lease, bool) {
+	l, ok := t.release(mac)
+	if ok && !l.Static {
+		t.declined[netip.MustParseAddr(l.IP)] = now.Add(dhcpDeclineHold)
+	}
+	return l, ok
+}
+
+// expire removes and returns the leases that ran out before now.
+func (t *leaseTable) expire(now time.Time) []dhcp.Lease {
+	var expired []dhcp.Lease
+	for mac, l := range t.leases {
+		if now.After(l.Expires) {
+			expired = append(expired, *l)
+			t.remove(mac)
+		}
+	}
+	for ip, until := range t.declined {
+		if now.After(until) {
+			delete(t.declined, ip)
+		}
+	}
+	return expired
+}
+
+// list returns the leases ordered by address.
+func (t *leaseTable) list() []dhcp.Lease {
+	leases := make([]dhcp.Lease, 0, len(t.leases))
+	for _, l := range t.leases {
+		leases = append(leases, *l)
+	}
+	slices.SortFunc(leases, func(a, b dhcp.Lease) int {
+		return netip.MustParseAddr(a.IP).Compare(netip.MustParseAddr(b.IP))
+	})
+	return leases
+}
+
+func (t *leaseTable) set(mac, hostname string, ip netip.Addr, state string, expires time.Time) dhcp.Lease {
+	if prev, ok := t.leases[mac]; ok && prev.IP != ip.String() {
+		delete(t.byIP, netip.MustParseAddr(prev.IP))
+	}
+	if owner, ok := t.byIP[ip]; ok && owner != mac {
+		// Expired lease of another client, free reported it as reusable.
+		delete(t.leases, owner)
+	}
+
+	_, static := t.reserved[mac]
+	l := &dhcp.Lease{
+		MAC:      mac,
+		IP:       ip.String(),
+		Hostname: hostname,
+		State:    state,
+		Static:   static,
+		Expires:  expires,
+	}
+	t.leases[mac] = l
+	t.byIP[ip] = mac
+	return *l
+}
+
+func (t *leaseTable) remove(mac string) {
+	if l, ok := t.leases[mac]; ok {
+		delete(t.byIP, netip.MustParseAddr(l.IP))
+		delete(t.leases, mac)
+	}
+}
@@ -0,0 +1,366 @@
+//go:build darwin || linux
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"macbox/pkg/dhcp"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const dhcpExpireInterval = 10 * time.Second
+
+// DHCPServerService runs a small DHCPv4 server on one interface, meant for
+// a device plugged straight into the laptop. It never relays and answers
+// every client on the link.
+type DHCPServerService struct {
+	ctx    context.Context
+	mu     sync.Mutex
+	state  dhcp.ServerState
+	table  *leaseTable
+	server *server4.Server
+	cancel context.CancelFunc
+}
+
+func NewDHCPServerService() *DHCPServerService {
+	return &DHCPServerService{}
+}
+
+func (s *DHCPServerService) SetContext(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+}
+
+func (s *DHCPServerService) GetState() dhcp.ServerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// snapshot copies the state with the current leases. Caller must hold the lock.
+func (s *DHCPServerService) snapshot() dhcp.ServerState {
+	state := s.state
+	state.Leases = []dhcp.Lease{}
+	if s.table != nil {
+		state.Leases = s.table.list()
+	}
+	return state
+}
+
+// RestoreConfig sets the configuration loaded from settings without
+// validating it, a half filled form is kept as the user left it.
+func (s *DHCPServerService) RestoreConfig(cfg dhcp.ServerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.state.Running {
+		s.state.Config = cfg
+	}
+}
+
+// SaveConfig validates and stores the configuration used by the next
+// Start. While the server runs only the reservations may change, they
+// take effect at once.
+func (s *DHCPServerService) SaveConfig(cfg dhcp.ServerConfig) string {
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+
+	s.mu.Lock()
+	var dropped []dhcp.Lease
+	if s.state.Running {
+		current := s.state.Config
+		current.Reservations = cfg.Reservations
+		if !sameServerConfig(current, cfg) {
+			s.mu.Unlock()
+			return "Stop the DHCP server to change its settings. Only reservations can be changed while it runs."
+		}
+		dropped = s.table.setReservations(cfg.Reservations)
+	}
+	s.state.Config = cfg
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	for _, l := range dropped {
+		emitLeaseEvent(ctx, "release", l)
+	}
+	return ""
+}
+
+// Start serves cfg on its device. cfg.ServerIP must already be assigned
+// to the device, the server does not configure the interface itself.
+func (s *DHCPServerService) Start(cfg dhcp.ServerConfig) string {
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.Running {
+		return "DHCP server is already running."
+	}
+	if err := checkServerAddress(cfg.Device, cfg.ServerIP); err != nil {
+		return err.Error()
+	}
+
+	conn, err := server4.NewIPv4UDPConn(cfg.Device, &net.UDPAddr{Port: dhcpv4.ServerPort})
+	if err != nil {
+		return fmt.Sprintf("Cannot listen on %s: %v", cfg.Device, err)
+	}
+	server, err := server4.NewServer("", nil, s.handle, server4.WithConn(conn))
+	if err != nil {
+		conn.Close()
+		return err.Error()
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.server, s.cancel = server, cancel
+	s.table = newLeaseTable(cfg)
+	s.state = dhcp.ServerState{Config: cfg, Running: true}
+	runtime.EventsEmit(s.ctx, "dhcp-server-state", s.snapshot())
+
+	go s.expireLoop(ctx)
+	go func() {
+		err := server.Serve()
+		s.stopped(server, err)
+	}()
+	return ""
+}
+
+func (s *DHCPServerService) Stop() {
+	s.mu.Lock()
+	server := s.server
+	s.mu.Unlock()
+
+	if server != nil {
+		server.Close()
+	}
+}
+
+// stopped cleans up after Serve of server returned. err is only reported
+// when the socket failed on its own, not after Stop.
+func (s *DHCPServerService) stopped(server *server4.Server, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != server {
+		return
+	}
+	s.cancel()
+	s.server, s.cancel = nil, nil
+	s.state.Running = false
+	s.state.Error = ""
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		s.state.Error = err.Error()
+	}
+	runtime.EventsEmit(s.ctx, "dhcp-server-state", s.snapshot())
+}
+
+// ReleaseLease drops the lease of mac so its address can be handed out
+// again. The client keeps using it until its next renewal, which fails.
+func (s *DHCPServerService) ReleaseLease(mac string) string {
+	s.mu.Lock()
+	if s.table == nil {
+		s.mu.Unlock()
+		return "DHCP server is not running."
+	}
+	lease, ok := s.table.release(mac)
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	if !ok {
+		return "Lease not found. It might have expired."
+	}
+	emitLeaseEvent(ctx, "release", lease)
+	return ""
+}
+
+func (s *DHCPServerService) expireLoop(ctx context.Context) {
+	ticker := time.NewTicker(dhcpExpireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			expired := s.table.expire(now)
+			s.mu.Unlock()
+
+			for _, l := range expired {
+				emitLeaseEvent(ctx, "expire", l)
+			}
+		}
+	}
+}
+
+func (s *DHCPServerService) handle(conn net.PacketConn, peer net.Addr, req *dhcpv4.DHCPv4) {
+	if req.OpCode != dhcpv4.OpcodeBootRequest || len(req.ClientHWAddr) != 6 {
+		return
+	}
+
+	s.mu.Lock()
+	if s.table == nil {
+		s.mu.Unlock()
+		return
+	}
+	reply, event, lease := s.answer(req, time.Now())
+	cfg, ctx := s.state.Config, s.ctx
+	s.mu.Unlock()
+
+	if event != "" {
+		emitLeaseEvent(ctx, event, lease)
+	}
+	if reply == nil {
+		return
+	}
+
+	// Clients without an address cannot receive unicast before ARP knows
+	// them, so anything not addressed to a configured client is broadcast.
+	dst := &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpv4.ClientPort}
+	if !req.ClientIPAddr.IsUnspecified() && reply.MessageType() != dhcpv4.MessageTypeNak {
+		dst.IP = req.ClientIPAddr
+	}
+	if _, err := conn.WriteTo(reply.ToBytes(), dst); err != nil {
+		fmt.Printf("DHCP reply to %s on %s failed: %v\n", req.ClientHWAddr, cfg.Device, err)
+	}
+}
+
+// answer runs req against the lease table and builds the reply, nil if
+// the request needs none. Caller must hold the lock.
+func (s *DHCPServerService) answer(req *dhcpv4.DHCPv4, now time.Time) (reply *dhcpv4.DHCPv4, event string, lease dhcp.Lease) {
+	cfg := s.state.Config
+	mac := req.ClientHWAddr.String()
+	server := net.ParseIP(cfg.ServerIP).To4()
+
+	switch req.MessageType() {
+	case dhcpv4.MessageTypeDiscover:
+		requested, _ := netip.AddrFromSlice(req.RequestedIPAddress().To4())
+		var ok bool
+		if lease, ok = s.table.offer(mac, req.HostName(), requested, now); !ok {
+			fmt.Printf("DHCP pool of %s is exhausted, no offer for %s\n", cfg.Device, mac)
+			return nil, "", lease
+		}
+		return dhcpReply(req, cfg, dhcpv4.MessageTypeOffer, lease.IP), "offer", lease
+
+	case dhcpv4.MessageTypeRequest:
+		if sid := req.ServerIdentifier(); sid != nil && !sid.Equal(server) {
+			// The client took the offer of another server on the link.
+			if l, ok := s.table.leases[mac]; ok && l.State == "offered" {
+				s.table.release(mac)
+			}
+			return nil, "", lease
+		}
+
+		ip := req.RequestedIPAddress()
+		if ip == nil || ip.IsUnspecified() {
+			ip = req.ClientIPAddr // renewing or rebinding
+		}
+		addr, _ := netip.AddrFromSlice(ip.To4())
+		lease, renewed, ok := s.table.request(mac, req.HostName(), addr, now)
+		if !ok {
+			return dhcpReply(req, cfg, dhcpv4.MessageTypeNak, ""), "", lease
+		}
+		event = "bind"
+		if renewed {
+			event = "renew"
+		}
+		return dhcpReply(req, cfg, dhcpv4.MessageTypeAck, lease.IP), event, lease
+
+	case dhcpv4.MessageTypeRelease:
+		if lease, ok := s.table.release(mac); ok {
+			return nil, "release", lease
+		}
+
+	case dhcpv4.MessageTypeDecline:
+		if lease, ok := s.table.decline(mac, now); ok {
+			return nil, "decline", lease
+		}
+
+	case dhcpv4.MessageTypeInform:
+		// The client has its address already and only wants options.
+		return dhcpReply(req, cfg, dhcpv4.MessageTypeAck, ""), "", lease
+	}
+	return nil, "", lease
+}
+
+// dhcpReply builds the answer to req. yourIP is empty for a NAK and for
+// the ACK of an INFORM, which carry no lease.
+func dhcpReply(req *dhcpv4.DHCPv4, cfg dhcp.ServerConfig, msgType dhcpv4.MessageType, yourIP string) *dhcpv4.DHCPv4 {
+	server := net.ParseIP(cfg.ServerIP).To4()
+	mods := []dhcpv4.Modifier{
+		dhcpv4.WithMessageType(msgType),
+		dhcpv4.WithOption(dhcpv4.OptServerIdentifier(server)),
+	}
+
+	if msgType != dhcpv4.MessageTypeNak {
+		mask := net.IPMask(net.ParseIP(cfg.SubnetMask).To4())
+		mods = append(mods, dhcpv4.WithNetmask(mask))
+		if cfg.Router != "" {
+			mods = append(mods, dhcpv4.WithOption(dhcpv4.OptRouter(net.ParseIP(cfg.Router))))
+		}
+		if len(cfg.DNS) > 0 {
+			dns := make([]net.IP, len(cfg.DNS))
+			for i, d := range cfg.DNS {
+				dns[i] = net.ParseIP(d)
+			}
+			mods = append(mods, dhcpv4.WithOption(dhcpv4.OptDNS(dns...)))
+		}
+	}
+	if yourIP != "" {
+		mods = append(mods,
+			dhcpv4.WithYourIP(net.ParseIP(yourIP)),
+			dhcpv4.WithLeaseTime(uint32(cfg.LeaseTime)),
+		)
+	}
+
+	reply, err := dhcpv4.NewReplyFromRequest(req, mods...)
+	if err != nil {
+		// Only fails without a random source for the transaction id,
+		// which a reply copies from the request anyway.
+		return nil
+	}
+	return reply
+}
+
+func emitLeaseEvent(ctx context.Context, kind string, lease dhcp.Lease) {
+	runtime.EventsEmit(ctx, "dhcp-lease", dhcp.LeaseEvent{Type: kind, Lease: lease, Time: time.Now()})
+}
+
+// checkServerAddress makes sure ip is configured on device, otherwise
+// clients would get a server identifier nobody answers on.
+func checkServerAddress(device, ip string) error {
+	ifi, err := net.InterfaceByName(device)
+	if err != nil {
+		return errors.New("Service or Device not found. It might have been deleted.")
+	}
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return err
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.String() == ip {
+			return nil
+		}
+	}
+	return fmt.Errorf("Assign %s to %s before starting the DHCP server.", ip, device)
+}
+
+// sameServerConfig compares everything but the reservations.
+func sameServerConfig(a, b dhcp.ServerConfig) bool {
+	return a.Device == b.Device && a.ServerIP == b.ServerIP && a.SubnetMask == b.SubnetMask &&
+		a.PoolStart == b.PoolStart && a.PoolEnd == b.PoolEnd && a.Router == b.Router &&
+		slices.Equal(a.DNS, b.DNS) && a.LeaseTime == b.LeaseTime
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"macbox/pkg/mavlink"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+	"github.com/bluenviron/gomavlib/v3/pkg/streamwriter"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	mavlinkSystemID          = 255 // the usual GCS id
+	mavlinkHeartbeatInterval = time.Second
+	mavlinkDialTimeout       = 2 * time.Second
+	mavlinkRedialInterval    = 3 * time.Second
+	// mavlinkVersionRequests is how often AUTOPILOT_VERSION is asked for,
+	// once per HEARTBEAT, before a component is taken as not sending it.
+	mavlinkVersionRequests = 3
+)
+
+// MAVLinkDiscoveryService listens on the usual MAVLink ports and lists
+// every system/component that sends a HEARTBEAT. It uses plain sockets
+// and the gomavlib frame codec rather than a gomavlib Node, the source
+// address of every packet is what the user is looking for.
+type MAVLinkDiscoveryService struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	state    mavlink.DiscoveryState
+	vehicles map[mavlinkKey]*mavlinkVehicle
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type mavlinkKey struct {
+	system, component int
+	source, endpoint  string
+}
+
+type mavlinkVehicle struct {
+	vehicle  mavlink.Vehicle
+	from     net.Addr
+	requests int
+}
+
+func NewMAVLinkDiscoveryService() *MAVLinkDiscoveryService {
+	return &MAVLinkDiscoveryService{
+		state: mavlink.DiscoveryState{Endpoints: []mavlink.Endpoint{}},
+	}
+}
+
+func (s *MAVLinkDiscoveryService) SetContext(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+}
+
+func (s *MAVLinkDiscoveryService) GetState() mavlink.DiscoveryState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// snapshot copies the state with the vehicles by system and component.
+// Caller must hold the lock.
+func (s *MAVLinkDiscoveryService) snapshot() mavlink.DiscoveryState {
+	state := s.state
+	state.Endpoints = slices.Clone(s.state.Endpoints)
+	state.Vehicles = make([]mavlink.Vehicle, 0, len(s.vehicles))
+	for _, v := range s.vehicles {
+		state.Vehicles = append(state.Vehicles, v.vehicle)
+	}
+	slices.SortFunc(state.Vehicles, func(a, b mavlink.Vehicle) int {
+		if a.SystemID != b.SystemID {
+			return a.SystemID - b.SystemID
+		}
+		if a.ComponentID != b.ComponentID {
+			return a.ComponentID - b.ComponentID
+		}
+		return strings.Compare(a.Source, b.Source)
+	})
+	return state
+}
+
+// RestoreConfig sets the configuration loaded from settings.
+func (s *MAVLinkDiscoveryService) RestoreConfig(cfg mavlink.DiscoveryConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.state.Running {
+		s.state.Config = cfg
+	}
+}
+
+// Start opens the standard and configured ports. A port that cannot be
+// opened, usually because the watcher or a GCS holds it, is reported on
+// its endpoint and the others still run.
+func (s *MAVLinkDiscoveryService) Start(cfg mavlink.DiscoveryConfig) string {
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.Running {
+		return "MAVLink discovery is already running."
+	}
+	rw := &dialect.ReadWriter{Dialect: common.Dialect}
+	if err := rw.Initialize(); err != nil {
+		return err.Error()
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancel = cancel
+	s.state = mavlink.DiscoveryState{Config: cfg, Running: true, Endpoints: []mavlink.Endpoint{}}
+	s.vehicles = map[mavlinkKey]*mavlinkVehicle{}
+
+	local := localAddrs()
+	for _, port := range cfg.UDPPortList() {
+		endpoint := mavlink.Endpoint{Transport: "udp", Address: ":" + strconv.Itoa(port)}
+		conn, err := net.ListenPacket("udp4", endpoint.Address)
+		if err != nil {
+			endpoint.Error = fmt.Sprintf("Cannot listen on port %d: %v", port, err)
+			s.state.Endpoints = append(s.state.Endpoints, endpoint)
+			continue
+		}
+		s.state.Endpoints = append(s.state.Endpoints, endpoint)
+
+		link, err := newMAVLinkLink(rw, "udp", endpoint.Address, func(data []byte, to net.Addr) error {
+			_, err := conn.WriteTo(data, to)
+			return err
+		})
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		context.AfterFunc(ctx, func() { conn.Close() })
+
+		s.wg.Add(2)
+		go func() {
+			defer s.wg.Done()
+			s.readUDP(conn, link, rw, port, local)
+		}()
+		go func() {
+			defer s.wg.Done()
+			s.heartbeatUDP(ctx, link, port, cfg.Broadcast)
+		}()
+	}
+	for _, address := range cfg.TCPAddressList() {
+		index := len(s.state.Endpoints)
+		s.state.Endpoints = append(s.state.Endpoints, mavlink.Endpoint{Transport: "tcp", Address: address})
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runTCP(ctx, index, address, rw)
+		}()
+	}
+
+	runtime.EventsEmit(s.ctx, "mavlink-discovery-state", s.snapshot())
+	return ""
+}
+
+// Stop closes all sockets. The vehicles found stay listed until the next
+// Start.
+func (s *MAVLinkDiscoveryService) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Running = false
+	for i := range s.state.Endpoints {
+		s.state.Endpoints[i].Connected = false
+	}
+	runtime.EventsEmit(s.ctx, "mavlink-discovery-state", s.snapshot())
+}
+
+func (s *MAVLinkDiscoveryService) readUDP(conn net.PacketConn, link *mavlinkLink, rw *dialect.ReadWriter, port int, local map[netip.Addr]bool) {
+	buffer := make([]byte, 65535)
+	for {
+		n, from, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		udp, ok := from.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		if addr, _ := netip.AddrFromSlice(udp.IP.To4()); local[addr] && udp.Port == port {
+			continue // our own broadcast HEARTBEAT
+		}
+
+		reader := &frame.Reader{BufByteReader: bufio.NewReader(bytes.NewReader(buffer[:n])), DialectRW: rw}
+		if reader.Initialize() != nil {
+			continue
+		}
+		for {
+			fr, err := reader.Read()
+			var readErr frame.ReadError
+			if errors.As(err, &readErr) {
+				continue
+			}
+			if err != nil {
+				break
+			}
+			s.handleFrame(link, from, fr)
+		}
+	}
+}
+
+// heartbeatUDP announces us to every vehicle already heard on the port,
+// some only keep streaming while a GCS is there, and to the broadcast
+// address of every interface to wake up vehicles that wait for a GCS.
+func (s *MAVLinkDiscoveryService) heartbeatUDP(ctx context.Context, link *mavlinkLink, port int, broadcast bool) {
+	ticker := time.NewTicker(mavlinkHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		var targets []net.Addr
+		if broadcast {
+			for _, ip := range broadcastAddrs() {
+				targets = append(targets, &net.UDPAddr{IP: ip, Port: port})
+			}
+		}
+		s.mu.Lock()
+		for _, v := range s.vehicles {
+			if v.vehicle.Endpoint == link.endpoint && !slices.ContainsFunc(targets, func(a net.Addr) bool { return a.String() == v.from.String() }) {
+				targets = append(targets, v.from)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, to := range targets {
+			_ = link.write(gcsHeartbeat(), to)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runTCP keeps a connection to address until ctx ends, redialing when it
+// drops. Nothing may be listening yet, SITL is often started later.
+func (s *MAVLinkDiscoveryService) runTCP(ctx context.Context, index int, address string, rw *dialect.ReadWriter) {
+	for {
+		dialer := net.Dialer{Timeout: mavlinkDialTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp4", address)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.setEndpoint(index, false, fmt.Sprintf("Cannot connect to %s: %v", address, err))
+		} else {
+			s.setEndpoint(index, true, "")
+			err = s.serveTCP(ctx, conn, address, rw)
+			s.setEndpoint(index, false, "")
+			if ctx.Err() != nil {
+				return
+			}
+			s.setEndpoint(index, false, fmt.Sprintf("Connection to %s closed: %v", address, err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(mavlinkRedialInterval):
+		}
+	}
+}
+
+func (s *MAVLinkDiscoveryService) serveTCP(ctx context.Context, conn net.Conn, address string, rw *dialect.ReadWriter) error {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	link, err := newMAVLinkLink(rw, "tcp", address, func(data []byte, _ net.Addr) error {
+		_, err := conn.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(mavlinkHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			_ = link.write(gcsHeartbeat(), nil)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	reader := &frame.Reader{BufByteReader: bufio.NewReader(conn), DialectRW: rw}
+	if err := reader.Initialize(); err != nil {
+		return err
+	}
+	for {
+		fr, err := reader.Read()
+		var readErr frame.ReadError
+		if errors.As(err, &readErr) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return errors.New("closed by the other side")
+		}
+		if err != nil {
+			return err
+		}
+		s.handleFrame(link, conn.RemoteAddr(), fr)
+	}
+}
+
+func (s *MAVLinkDiscoveryService) setEndpoint(index int, connected bool, errMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	endpoint := &s.state.Endpoints[index]
+	if endpoint.Connected == connected && endpoint.Error == errMsg {
+		return
+	}
+	endpoint.Connected, endpoint.Error = connected, errMsg
+	runtime.EventsEmit(s.ctx, "mavlink-discovery-state", s.snapshot())
+}
+
+func (s *MAVLinkDiscoveryService) handleFrame(link *mavlinkLink, from net.Addr, fr frame.Frame) {
+	key := mavlinkKey{
+		system:    int(fr.GetSystemID()),
+		component: int(fr.GetComponentID()),
+		source:    from.String(),
+		endpoint:  link.endpoint,
+	}
+
+	switch msg := fr.GetMessage().(type) {
+	case *common.MessageHeartbeat:
+		s.heartbeat(link, key, from, fr, msg)
+	case *common.MessageAutopilotVersion:
+		s.mu.Lock()
+		v, ok := s.vehicles[key]
+		if !ok {
+			// Not asked for, the HEARTBEAT comes first.
+			s.mu.Unlock()
+			return
+		}
+		v.vehicle.Firmware = firmwareVersion(msg.FlightSwVersion)
+		vehicle := v.vehicle
+		s.mu.Unlock()
+
+		runtime.EventsEmit(s.ctx, "mavlink-vehicle", vehicle)
+	}
+}
+
+// heartbeat adds or updates the vehicle and asks a flight controller for
+// its firmware version until it answers.
+func (s *MAVLinkDiscoveryService) heartbeat(link *mavlinkLink, key mavlinkKey, from net.Addr, fr frame.Frame, msg *common.MessageHeartbeat) {
+	version := 2
+	if _, ok := fr.(*frame.V1Frame); ok {
+		version = 1
+	}
+
+	s.mu.Lock()
+	v, ok := s.vehicles[key]
+	if !ok {
+		v = &mavlinkVehicle{vehicle: mavlink.Vehicle{
+			SystemID:    key.system,
+			ComponentID: key.component,
+			Transport:   link.transport,
+			Source:      key.source,
+			Endpoint:    key.endpoint,
+		}}
+		s.vehicles[key] = v
+	}
+	v.from = from
+	v.vehicle.Autopilot = enumLabel(msg.Autopilot.String(), "MAV_AUTOPILOT_", int(msg.Autopilot))
+	v.vehicle.Type = enumLabel(msg.Type.String(), "MAV_TYPE_", int(msg.Type))
+	v.vehicle.State = enumLabel(msg.SystemStatus.String(), "MAV_STATE_", int(msg.SystemStatus))
+	v.vehicle.Armed = msg.BaseMode&common.MAV_MODE_FLAG_SAFETY_ARMED != 0
+	v.vehicle.MAVLinkVersion = version
+	v.vehicle.LastSeen = time.Now()
+
+	request := v.vehicle.Firmware == "" && msg.Autopilot != common.MAV_AUTOPILOT_INVALID && v.requests < mavlinkVersionRequests
+	if request {
+		v.requests++
+	}
+	attempt := v.requests
+	vehicle := v.vehicle
+	s.mu.Unlock()
+
+	runtime.EventsEmit(s.ctx, "mavlink-vehicle", vehicle)
+	if request {
+		_ = link.write(versionRequest(key, attempt), from)
+	}
+}
+
+// versionRequest asks for AUTOPILOT_VERSION, every second attempt with
+// the older command firmware before MAV_CMD_REQUEST_MESSAGE understands.
+func versionRequest(key mavlinkKey, attempt int) *common.MessageCommandLong {
+	cmd := &common.MessageCommandLong{
+		TargetSystem:    uint8(key.system),
+		TargetComponent: uint8(key.component),
+		Command:         common.MAV_CMD_REQUEST_MESSAGE,
+		Param1:          float32((&common.MessageAutopilotVersion{}).GetID()),
+	}
+	if attempt%2 == 0 {
+		cmd.Command = common.MAV_CMD_REQUEST_AUTOPILOT_CAPABILITIES
+		cmd.Param1 = 1
+	}
+	return cmd
+}
+
+func gcsHeartbeat() *common.MessageHeartbeat {
+	return &common.MessageHeartbeat{
+		Type:           common.MAV_TYPE_GCS,
+		Autopilot:      common.MAV_AUTOPILOT_INVALID,
+		SystemStatus:   common.MAV_STATE_ACTIVE,
+		MavlinkVersion: 3,
+	}
+}
+
+// firmwareVersion decodes flight_sw_version: major, minor, patch and
+// FIRMWARE_VERSION_TYPE from the most to the least significant byte.
+func firmwareVersion(v uint32) string {
+	if v == 0 {
+		return "unknown"
+	}
+	version := fmt.Sprintf("%d.%d.%d", v>>24, v>>16&0xff, v>>8&0xff)
+	switch common.FIRMWARE_VERSION_TYPE(v & 0xff) {
+	case common.FIRMWARE_VERSION_TYPE_DEV:
+		version += "-dev"
+	case common.FIRMWARE_VERSION_TYPE_ALPHA:
+		version += "-alpha"
+	case common.FIRMWARE_VERSION_TYPE_BETA:
+		version += "-beta"
+	case common.FIRMWARE_VERSION_TYPE_RC:
+		version += "-rc"
+	}
+	return version
+}
+
+// enumLabel strips the enum prefix, values the dialect does not know
+// have no name and are shown as numbers.
+func enumLabel(name, prefix string, value int) string {
+	if name == "" {
+		return strconv.Itoa(value)
+	}
+	return strings.TrimPrefix(name, prefix)
+}
+
+// mavlinkLink encodes messages for one socket. UDP links answer to the
+// address a vehicle sent from, TCP links ignore the address.
+type mavlinkLink struct {
+	transport string
+	endpoint  string
+	send      func(data []byte, to net.Addr) error
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writer *streamwriter.Writer
+}
+
+func newMAVLinkLink(rw *dialect.ReadWriter, transport, endpoint string, send func([]byte, net.Addr) error) (*mavlinkLink, error) {
+	l := &mavlinkLink{transport: transport, endpoint: endpoint, send: send}
+	frameWriter := &frame.Writer{ByteWriter: &l.buf, DialectRW: rw}
+	if err := frameWriter.Initialize(); err != nil {
+		return nil, err
+	}
+	l.writer = &streamwriter.Writer{
+		FrameWriter: frameWriter,
+		Version:     streamwriter.V2,
+		SystemID:    mavlinkSystemID,
+		ComponentID: byte(common.MAV_COMP_ID_MISSIONPLANNER),
+	}
+	if err := l.writer.Initialize(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *mavlinkLink) write(msg message.Message, to net.Addr) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf.Reset()
+	if err := l.writer.Write(msg); err != nil {
+		return err
+	}
+	return l.send(l.buf.Bytes(), to)
+}
+
+// localAddrs are the IPv4 addresses of this machine.
+func localAddrs() map[netip.Addr]bool {
+	local := map[netip.Addr]bool{}
+	addrs, _ := net.InterfaceAddrs()
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok {
+			if addr, ok := netip.AddrFromSlice(ipnet.IP.To4()); ok {
+				local[addr] = true
+			}
+		}
+	}
+	return local
+}
+
+// broadcastAddrs are the directed broadcast addresses of the interfaces
+// that are up. 255.255.255.255 would only leave through one of them.
+func broadcastAddrs() []net.IP {
+	var out []net.IP
+	ifaces, _ := net.Interfaces()
+	for _, ifi := range ifaces {
+		if ifi.Flags&net.FlagUp == 0 || ifi.Flags&net.FlagBroadcast == 0 || ifi.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, _ := ifi.Addrs()
+		for _, a := range addrs {
+			ipnet, ok := a.(*net.IPNet)
+			if !ok || ipnet.IP.To4() == nil || len(ipnet.Mask) != net.IPv4len {
+				continue
+			}
+			ip := make(net.IP, net.IPv4len)
+			for i := range ip {
+				ip[i] = ipnet.IP.To4()[i] | ^ipnet.Mask[i]
+			}
+			out = append(out, ip)
+		}
+	}
+	return out
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"macbox/pkg/mdns"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/libp2p/zeroconf/v2"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// dnssdEnumeration is the meta type every responder answers with the
+// types it advertises (RFC 6763 section 9).
+const dnssdEnumeration = "_services._dns-sd._udp"
+
+// MDNSService browses and advertises DNS-SD services over multicast DNS.
+// It speaks mDNS itself, neither Avahi nor mDNSResponder is needed.
+// Instances that go away are not reported, their Expires tells how long
+// the last answer is valid.
+type MDNSService struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	state    mdns.BrowserState
+	services map[string]mdns.Service // by type and instance
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	servers       map[string]*zeroconf.Server // by type and instance
+	registrations map[string]mdns.Registration
+}
+
+func NewMDNSService() *MDNSService {
+	return &MDNSService{
+		state:         mdns.BrowserState{Types: []string{}},
+		services:      map[string]mdns.Service{},
+		servers:       map[string]*zeroconf.Server{},
+		registrations: map[string]mdns.Registration{},
+	}
+}
+
+func (s *MDNSService) SetContext(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+}
+
+func (s *MDNSService) GetState() mdns.BrowserState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// snapshot copies the state with the services and registrations by type
+// and name. Caller must hold the lock.
+func (s *MDNSService) snapshot() mdns.BrowserState {
+	state := s.state
+	state.Types = slices.Clone(s.state.Types)
+	slices.Sort(state.Types)
+
+	state.Services = make([]mdns.Service, 0, len(s.services))
+	for _, svc := range s.services {
+		state.Services = append(state.Services, svc)
+	}
+	slices.SortFunc(state.Services, func(a, b mdns.Service) int {
+		return strings.Compare(serviceKey(a.Type, a.Instance), serviceKey(b.Type, b.Instance))
+	})
+
+	state.Registrations = make([]mdns.Registration, 0, len(s.registrations))
+	for _, r := range s.registrations {
+		state.Registrations = append(state.Registrations, r)
+	}
+	slices.SortFunc(state.Registrations, func(a, b mdns.Registration) int {
+		return strings.Compare(serviceKey(a.Type, a.Instance), serviceKey(b.Type, b.Instance))
+	})
+	return state
+}
+
+func serviceKey(serviceType, instance string) string {
+	return serviceType + "/" + instance
+}
+
+// Browse looks for services until StopBrowse. Types found by the
+// enumeration arrive as "mdns-type" events, resolved instances as
+// "mdns-service" events.
+func (s *MDNSService) Browse(req mdns.BrowseRequest) string {
+	if errs := req.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+	var opts []zeroconf.ClientOption
+	if req.Interface != "" {
+		ifi, err := net.InterfaceByName(req.Interface)
+		if err != nil {
+			return "Service or Device not found. It might have been deleted."
+		}
+		opts = append(opts, zeroconf.SelectIfaces([]net.Interface{*ifi}))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.Browsing {
+		return "Browsing is already running."
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancel = cancel
+	s.state = mdns.BrowserState{Browsing: true, Request: req, Types: []string{}}
+	s.services = map[string]mdns.Service{}
+
+	if len(req.Types) == 0 {
+		s.browse(ctx, dnssdEnumeration, req.Domain, opts, func(e *zeroconf.ServiceEntry) {
+			// The instance of an enumeration answer is the type,
+			// "_http._tcp.local".
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.browseType(ctx, mdns.NormalizeType(e.Instance), req.Domain, opts)
+		})
+	}
+	for _, t := range req.Types {
+		s.browseType(ctx, t, req.Domain, opts)
+	}
+
+	runtime.EventsEmit(s.ctx, "mdns-state", s.snapshot())
+	return ""
+}
+
+// browseType starts browsing t once per Browse. Caller must hold the lock.
+func (s *MDNSService) browseType(ctx context.Context, t, domain string, opts []zeroconf.ClientOption) {
+	if slices.Contains(s.state.Types, t) || ctx.Err() != nil {
+		return
+	}
+	s.state.Types = append(s.state.Types, t)
+	runtime.EventsEmit(s.ctx, "mdns-type", t)
+
+	s.browse(ctx, t, domain, opts, func(e *zeroconf.ServiceEntry) {
+		svc := serviceFromEntry(t, e)
+
+		s.mu.Lock()
+		s.services[serviceKey(svc.Type, svc.Instance)] = svc
+		s.mu.Unlock()
+
+		runtime.EventsEmit(s.ctx, "mdns-service", svc)
+	})
+}
+
+// browse runs zeroconf.Browse for t and hands every entry to found. The
+// entries channel is only closed by zeroconf if its client started, the
+// reader also stops when Browse returns.
+func (s *MDNSService) browse(ctx context.Context, t, domain string, opts []zeroconf.ClientOption, found func(*zeroconf.ServiceEntry)) {
+	entries := make(chan *zeroconf.ServiceEntry)
+	finished := make(chan struct{})
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		err := zeroconf.Browse(ctx, t, domain, entries, opts...)
+		close(finished)
+		if err != nil && ctx.Err() == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.state.Error = fmt.Sprintf("Cannot browse %s: %v", t, err)
+			runtime.EventsEmit(s.ctx, "mdns-state", s.snapshot())
+		}
+	}()
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case e, ok := <-entries:
+				if !ok {
+					return
+				}
+				found(e)
+			case <-finished:
+				return
+			}
+		}
+	}()
+}
+
+// StopBrowse ends browsing. The services found stay listed until the
+// next Browse.
+func (s *MDNSService) StopBrowse() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Browsing = false
+	runtime.EventsEmit(s.ctx, "mdns-state", s.snapshot())
+}
+
+// Register advertises a test service under this machine's host name
+// until Unregister.
+func (s *MDNSService) Register(r mdns.Registration) string {
+	if errs := r.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+	var ifaces []net.Interface
+	if r.Interface != "" {
+		ifi, err := net.InterfaceByName(r.Interface)
+		if err != nil {
+			return "Service or Device not found. It might have been deleted."
+		}
+		ifaces = append(ifaces, *ifi)
+	}
+	if r.TXT == nil {
+		r.TXT = []string{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := serviceKey(r.Type, r.Instance)
+	if _, ok := s.servers[key]; ok {
+		return fmt.Sprintf("%s is already registered as %s.", r.Instance, r.Type)
+	}
+	server, err := zeroconf.Register(r.Instance, r.Type, "local.", r.Port, r.TXT, ifaces)
+	if err != nil {
+		return fmt.Sprintf("Cannot register %s: %v", r.Instance, err)
+	}
+	s.servers[key] = server
+	s.registrations[key] = r
+
+	runtime.EventsEmit(s.ctx, "mdns-state", s.snapshot())
+	return ""
+}
+
+// Unregister sends the goodbye for a test service and stops answering
+// for it.
+func (s *MDNSService) Unregister(instance, serviceType string) string {
+	key := serviceKey(mdns.NormalizeType(serviceType), instance)
+
+	s.mu.Lock()
+	server, ok := s.servers[key]
+	delete(s.servers, key)
+	delete(s.registrations, key)
+	s.mu.Unlock()
+
+	if !ok {
+		return "Service not found. It might have been unregistered."
+	}
+	server.Shutdown()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runtime.EventsEmit(s.ctx, "mdns-state", s.snapshot())
+	return ""
+}
+
+func serviceFromEntry(t string, e *zeroconf.ServiceEntry) mdns.Service {
+	svc := mdns.Service{
+		Instance: unescapeDNSLabel(e.Instance),
+		Type:     t,
+		Domain:   strings.TrimSuffix(e.Domain, "."),
+		HostName: strings.TrimSuffix(e.HostName, "."),
+		Port:     e.Port,
+		TXT:      []string{},
+		IPv4:     []string{},
+		IPv6:     []string{},
+		Expires:  e.Expiry,
+	}
+	svc.TXT = append(svc.TXT, e.Text...)
+	for _, ip := range e.AddrIPv4 {
+		svc.IPv4 = append(svc.IPv4, ip.String())
+	}
+	for _, ip := range e.AddrIPv6 {
+		svc.IPv6 = append(svc.IPv6, ip.String())
+	}
+	return svc
+}
+
+// unescapeDNSLabel turns the presentation form of an instance name back
+// into what was advertised: "Camera\ 1" or "Caf\195\169" (RFC 4343).
+func unescapeDNSLabel(label string) string {
+	if !strings.Contains(label, `\`) {
+		return label
+	}
+	var b strings.Builder
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if c != '\\' || i+1 >= len(label) {
+			b.WriteByte(c)
+			continue
+		}
+		if i+3 < len(label) && isDigits(label[i+1:i+4]) {
+			n, _ := strconv.Atoi(label[i+1 : i+4])
+			b.WriteByte(byte(n))
+			i += 3
+			continue
+		}
+		b.WriteByte(label[i+1])
+		i++
+	}
+	return b.String()
+}
+
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"macbox/internal/tools"
+	"macbox/pkg/network"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	defaultConfirmTimeout = 30 * time.Second
+	minConfirmTimeout     = 5 * time.Second
+	confirmProbeInterval  = time.Second
+)
+
+// confirmGuard holds an applied change until the user confirms it or a
+// ping target answers. If neither happens in time the change is reverted,
+// so a bad static IP cannot lock the user out.
+type confirmGuard struct {
+	mu      sync.Mutex
+	pending *pendingChange
+}
+
+type pendingChange struct {
+	info   network.PendingChange
+	tx     *networkTx
+	cancel context.CancelFunc
+}
+
+var errChangePending = errors.New("Another network change is waiting for confirmation. Confirm or revert it first.")
+
+// busy returns errChangePending while a change is waiting.
+func (g *confirmGuard) busy() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pending != nil {
+		return errChangePending
+	}
+	return nil
+}
+
+// reserve claims the pending slot before a change runs, so no other
+// change can start until the returned slot is armed or released.
+func (g *confirmGuard) reserve() (*pendingChange, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pending != nil {
+		return nil, errChangePending
+	}
+	g.pending = &pendingChange{}
+	return g.pending, nil
+}
+
+// release frees a reserved slot whose change did not run.
+func (g *confirmGuard) release(p *pendingChange) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pending == p {
+		g.pending = nil
+	}
+}
+
+// arm starts waiting for confirmation of a transaction that already ran
+// in the reserved slot p.
+func (g *confirmGuard) arm(ctx context.Context, p *pendingChange, tx *networkTx, description string, opts network.ConfirmOptions) {
+	timeout := time.Duration(opts.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultConfirmTimeout
+	}
+	timeout = max(timeout, minConfirmTimeout)
+
+	wctx, cancel := context.WithCancel(ctx)
+
+	g.mu.Lock()
+	p.info = network.PendingChange{
+		Description: description,
+		Deadline:    time.Now().Add(timeout),
+		PingTarget:  opts.PingTarget,
+	}
+	p.tx = tx
+	p.cancel = cancel
+	g.mu.Unlock()
+
+	runtime.EventsEmit(ctx, "network-change-pending", p.info)
+	go g.watch(ctx, wctx, p, timeout)
+}
+
+func (g *confirmGuard) watch(ctx, wctx context.Context, p *pendingChange, timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	answered := make(chan struct{})
+	if target := p.info.PingTarget; target != "" {
+		go func() {
+			for {
+				if tools.Reachable(wctx, target, confirmProbeInterval) {
+					close(answered)
+					return
+				}
+				select {
+				case <-wctx.Done():
+					return
+				case <-time.After(confirmProbeInterval):
+				}
+			}
+		}()
+	}
+
+	select {
+	case <-wctx.Done():
+		// confirmed or reverted by the user
+	case <-answered:
+		if g.take(p) {
+			runtime.EventsEmit(ctx, "network-change-confirmed", "ping")
+		}
+	case <-timer.C:
+		if g.take(p) {
+			runtime.EventsEmit(ctx, "network-change-reverted", errString(p.tx.revert()))
+		}
+	}
+}
+
+// take clears p if it is still the pending change. Only the caller that
+// gets true may confirm or revert it.
+func (g *confirmGuard) take(p *pendingChange) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pending != p {
+		return false
+	}
+	g.pending = nil
+	p.cancel()
+	return true
+}
+
+// current returns the armed pending change, nil while there is none or
+// its change is still running.
+func (g *confirmGuard) current() *pendingChange {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.pending == nil || g.pending.tx == nil {
+		return nil
+	}
+	return g.pending
+}
+
+func (g *confirmGuard) confirm(ctx context.Context) string {
+	p := g.current()
+	if p == nil || !g.take(p) {
+		return "Nothing to confirm"
+	}
+	runtime.EventsEmit(ctx, "network-change-confirmed", "user")
+	return ""
+}
+
+func (g *confirmGuard) revertNow(ctx context.Context) string {
+	p := g.current()
+	if p == nil || !g.take(p) {
+		return "Nothing to revert"
+	}
+	msg := errString(p.tx.revert())
+	runtime.EventsEmit(ctx, "network-change-reverted", msg)
+	return msg
+}
+
+func (g *confirmGuard) info() *network.PendingChange {
+	if p := g.current(); p != nil {
+		info := p.info
+		return &info
+	}
+	return nil
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,139 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+const resolvConfPath = "/etc/resolv.conf"
+
+// getLinkDNS returns the DNS servers and search domains of a link.
+// systemd-resolved keeps them per link; without it the global
+// resolv.conf values are reported for every link.
+func getLinkDNS(dev string) ([]string, []string) {
+	servers := resolverCache.get("dns/"+dev, func() []string {
+		if hasResolvectl() {
+			return resolvectlList("dns", dev)
+		}
+		return readResolvConf("nameserver")
+	})
+	domains := resolverCache.get("domain/"+dev, func() []string {
+		if !hasResolvectl() {
+			return readResolvConf("search")
+		}
+		domains := []string{}
+		for _, d := range resolvectlList("domain", dev) {
+			// "~example.com" is a routing-only domain, not a search domain
+			if !strings.HasPrefix(d, "~") {
+				domains = append(domains, d)
+			}
+		}
+		return domains
+	})
+	return slices.Clone(servers), slices.Clone(domains)
+}
+
+// setLinkDNS and setLinkDomains drop every cached link, without
+// systemd-resolved the change applies to all of them.
+func setLinkDNS(dev string, servers []string) error {
+	defer resolverCache.reset()
+	if hasResolvectl() {
+		return resolvectlSet("dns", dev, servers)
+	}
+	return writeResolvConf("nameserver", servers)
+}
+
+func setLinkDomains(dev string, domains []string) error {
+	defer resolverCache.reset()
+	if hasResolvectl() {
+		return resolvectlSet("domain", dev, domains)
+	}
+	return writeResolvConf("search", domains)
+}
+
+func hasResolvectl() bool {
+	_, err := exec.LookPath("resolvectl")
+	return err == nil
+}
+
+// resolvectlList parses "Link 2 (eth0): 1.1.1.1 8.8.8.8".
+func resolvectlList(cmd, dev string) []string {
+	out, err := exec.Command("resolvectl", cmd, dev).Output()
+	if err != nil {
+		return []string{}
+	}
+	_, list, _ := strings.Cut(strings.TrimSpace(string(out)), "):")
+	return append([]string{}, strings.Fields(list)...)
+}
+
+func resolvectlSet(cmd, dev string, values []string) error {
+	args := []string{cmd, dev}
+	if len(values) == 0 {
+		// an empty argument clears the list
+		args = append(args, "")
+	}
+	return runCommand("resolvectl", append(args, values...)...)
+}
+
+func readResolvConf(key string) []string {
+	values := []string{}
+
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 1 && fields[0] == key {
+			values = append(values, fields[1:]...)
+		}
+	}
+	return values
+}
+
+// writeResolvConf replaces every line of the given key, keeping the rest
+// of the file (comments, options) as it is. A symlinked resolv.conf
+// belongs to a resolver manager that would overwrite the change, it is
+// left alone.
+func writeResolvConf(key string, values []string) error {
+	if fi, err := os.Lstat(resolvConfPath); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		target, _ := os.Readlink(resolvConfPath)
+		return fmt.Errorf("%s links to %s, which is managed by another program. Change DNS there", resolvConfPath, target)
+	}
+
+	data, err := os.ReadFile(resolvConfPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == key {
+			continue
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	switch {
+	case key == "search" && len(values) > 0:
+		lines = append(lines, "search "+strings.Join(values, " "))
+	case key == "nameserver":
+		for _, v := range values {
+			lines = append(lines, "nameserver "+v)
+		}
+	}
+
+	return os.WriteFile(resolvConfPath, []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
@@ -0,0 +1,65 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// getDNSServers returns the manually set DNS servers of a service,
+// an empty list when there are none.
+func getDNSServers(serviceName string) []string {
+	return getResolverList("-getdnsservers", serviceName)
+}
+
+func getSearchDomains(serviceName string) []string {
+	return getResolverList("-getsearchdomains", serviceName)
+}
+
+// getResolverList returns the cached output of -getdnsservers or
+// -getsearchdomains, readResolverList parses the one-entry-per-line
+// output they share.
+func getResolverList(flag, serviceName string) []string {
+	return slices.Clone(resolverCache.get(flag+"/"+serviceName, func() []string {
+		return readResolverList(flag, serviceName)
+	}))
+}
+
+func readResolverList(flag, serviceName string) []string {
+	list := []string{}
+
+	out, err := exec.Command("networksetup", flag, serviceName).Output()
+	if err != nil {
+		return list
+	}
+
+	// There aren't any DNS Servers set on Wi-Fi.
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.Contains(line, " ") {
+			continue
+		}
+		list = append(list, line)
+	}
+	return list
+}
+
+func setDNSServers(serviceName string, servers []string) error {
+	defer resolverCache.forget("-getdnsservers/" + serviceName)
+	if len(servers) == 0 {
+		return networksetup("-setdnsservers", serviceName, "Empty")
+	}
+	return networksetup(append([]string{"-setdnsservers", serviceName}, servers...)...)
+}
+
+func setSearchDomains(serviceName string, domains []string) error {
+	defer resolverCache.forget("-getsearchdomains/" + serviceName)
+	if len(domains) == 0 {
+		return networksetup("-setsearchdomains", serviceName, "Empty")
+	}
+	return networksetup(append([]string{"-setsearchdomains", serviceName}, domains...)...)
+}
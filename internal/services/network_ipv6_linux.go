@@ -0,0 +1,212 @@
+//go:build linux
+
+package services
+
+import (
+	"fmt"
+	"macbox/pkg/network"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// The IPv6 method of a link is not stored anywhere as such, it follows
+// from the per-link sysctls and the kind of global addresses present:
+//
+//	disable_ipv6=1              Off
+//	permanent global address    Manual
+//	accept_ra=0                 Link-local only
+//	otherwise                   Automatic (SLAAC / DHCPv6)
+
+func getLinkIPv6(link netlink.Link) network.IPv6Config {
+	dev := link.Attrs().Name
+	cfg := network.IPv6Config{Method: "Automatic", Addresses: []network.IPv6Address{}}
+
+	if readIPv6Conf(dev, "disable_ipv6") == "1" {
+		cfg.Method = "Off"
+		return cfg
+	}
+
+	addrs, _ := netlink.AddrList(link, netlink.FAMILY_V6)
+	manual := false
+	for _, a := range addrs {
+		ones, _ := a.Mask.Size()
+		cfg.Addresses = append(cfg.Addresses, network.IPv6Address{IP: a.IP.String(), PrefixLength: ones})
+		if !a.IP.IsLinkLocalUnicast() && a.Flags&unix.IFA_F_PERMANENT != 0 {
+			manual = true
+		}
+	}
+
+	switch {
+	case manual:
+		cfg.Method = "Manual"
+	case readIPv6Conf(dev, "accept_ra") == "0":
+		cfg.Method = "Link-local only"
+	}
+
+	routes, _ := netlink.RouteList(link, netlink.FAMILY_V6)
+	if gw := defaultGateway(link.Attrs().Index, routes); gw != nil {
+		cfg.Router = gw.String()
+	}
+
+	return cfg
+}
+
+// linkIPv6State is what addLinkIPv6Steps needs to undo its change.
+type linkIPv6State struct {
+	disabled string
+	acceptRA string
+	global   []netlink.Addr
+	router   net.IP
+}
+
+func captureLinkIPv6(link netlink.Link) (linkIPv6State, error) {
+	dev := link.Attrs().Name
+	state := linkIPv6State{
+		disabled: readIPv6Conf(dev, "disable_ipv6"),
+		acceptRA: readIPv6Conf(dev, "accept_ra"),
+	}
+
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V6)
+	if err != nil {
+		return state, err
+	}
+	for _, a := range addrs {
+		if !a.IP.IsLinkLocalUnicast() {
+			state.global = append(state.global, netlink.Addr{IPNet: a.IPNet})
+		}
+	}
+
+	routes, _ := netlink.RouteList(link, netlink.FAMILY_V6)
+	state.router = defaultGateway(link.Attrs().Index, routes)
+	return state, nil
+}
+
+// addLinkIPv6Steps queues the IPv6 change of one link with a restore of
+// the current sysctls, global addresses and default router.
+func addLinkIPv6Steps(tx *networkTx, link netlink.Link, v6 network.IPv6Update) error {
+	prev, err := captureLinkIPv6(link)
+	if err != nil {
+		return err
+	}
+
+	tx.add("set IPv6",
+		func() error { return setLinkIPv6(link, v6) },
+		func() error { return restoreLinkIPv6(link, prev) },
+	)
+	return nil
+}
+
+func setLinkIPv6(link netlink.Link, v6 network.IPv6Update) error {
+	dev := link.Attrs().Name
+
+	if v6.Method == "Off" {
+		return writeIPv6Conf(dev, "disable_ipv6", "1")
+	}
+	if err := writeIPv6Conf(dev, "disable_ipv6", "0"); err != nil {
+		return err
+	}
+
+	switch v6.Method {
+	case "Automatic":
+		if err := writeIPv6Conf(dev, "accept_ra", "1"); err != nil {
+			return err
+		}
+		// Keep what SLAAC already configured, drop the static addresses.
+		return replaceLinkIPv6(link, nil, nil, true)
+	case "Link-local only":
+		if err := writeIPv6Conf(dev, "accept_ra", "0"); err != nil {
+			return err
+		}
+		return replaceLinkIPv6(link, nil, nil, false)
+	case "Manual":
+		if err := writeIPv6Conf(dev, "accept_ra", "0"); err != nil {
+			return err
+		}
+		addr := netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP(v6.IP), Mask: net.CIDRMask(v6.PrefixLength, 128)}}
+		var router net.IP
+		if v6.Router != "" {
+			router = net.ParseIP(v6.Router)
+		}
+		return replaceLinkIPv6(link, []netlink.Addr{addr}, router, false)
+	}
+	return fmt.Errorf("unknown IPv6 method %q", v6.Method)
+}
+
+func restoreLinkIPv6(link netlink.Link, prev linkIPv6State) error {
+	dev := link.Attrs().Name
+	if err := writeIPv6Conf(dev, "disable_ipv6", prev.disabled); err != nil {
+		return err
+	}
+	if prev.disabled == "1" {
+		return nil
+	}
+	if err := writeIPv6Conf(dev, "accept_ra", prev.acceptRA); err != nil {
+		return err
+	}
+	return replaceLinkIPv6(link, prev.global, prev.router, false)
+}
+
+// replaceLinkIPv6 swaps the global IPv6 addresses of the link for addrs,
+// link-local addresses are left alone. With keepDynamic set, addresses
+// that carry a lifetime (SLAAC, DHCPv6) stay as well. The default route
+// is replaced by one via router, or removed if router is nil.
+func replaceLinkIPv6(link netlink.Link, addrs []netlink.Addr, router net.IP, keepDynamic bool) error {
+	current, err := netlink.AddrList(link, netlink.FAMILY_V6)
+	if err != nil {
+		return err
+	}
+	for i := range current {
+		a := &current[i]
+		if a.IP.IsLinkLocalUnicast() || keepDynamic && a.Flags&unix.IFA_F_PERMANENT == 0 {
+			continue
+		}
+		if err := netlink.AddrDel(link, a); err != nil {
+			return err
+		}
+	}
+	for i := range addrs {
+		if err := netlink.AddrReplace(link, &addrs[i]); err != nil {
+			return err
+		}
+	}
+
+	defaultDst := &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+	if router != nil {
+		return netlink.RouteReplace(&netlink.Route{LinkIndex: link.Attrs().Index, Dst: defaultDst, Gw: router})
+	}
+	if keepDynamic {
+		// The router advertisement owns the default route.
+		return nil
+	}
+
+	routes, _ := netlink.RouteList(link, netlink.FAMILY_V6)
+	for i := range routes {
+		r := &routes[i]
+		if r.Gw != nil && (r.Dst == nil || r.Dst.IP.IsUnspecified()) {
+			if err := netlink.RouteDel(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readIPv6Conf(dev, key string) string {
+	data, err := os.ReadFile(filepath.Join("/proc/sys/net/ipv6/conf", dev, key))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func writeIPv6Conf(dev, key, value string) error {
+	if value == "" {
+		return nil
+	}
+	return os.WriteFile(filepath.Join("/proc/sys/net/ipv6/conf", dev, key), []byte(value+"\n"), 0o644)
+}
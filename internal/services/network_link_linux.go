@@ -0,0 +1,168 @@
+//go:build linux
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"macbox/pkg/network"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/safchain/ethtool"
+	"github.com/vishvananda/netlink"
+)
+
+// Link modes of struct ethtool_cmd, see linux/ethtool.h.
+const (
+	ethtoolDuplexHalf = 0x00
+	ethtoolDuplexFull = 0x01
+	ethtoolSpeedMax   = 0xffff // SPEED_UNKNOWN in the 16 bit field
+)
+
+// ethtoolModes maps the "supported" bits to media names in the ifconfig
+// style used on macOS.
+var ethtoolModes = []struct {
+	bit   uint32
+	media string
+}{
+	{1 << 0, "10baseT <half-duplex>"},
+	{1 << 1, "10baseT <full-duplex>"},
+	{1 << 2, "100baseTX <half-duplex>"},
+	{1 << 3, "100baseTX <full-duplex>"},
+	{1 << 4, "1000baseT <half-duplex>"},
+	{1 << 5, "1000baseT <full-duplex>"},
+	{1 << 15, "2500baseX <full-duplex>"},
+	{1 << 12, "10Gbase-T <full-duplex>"},
+}
+
+const ethtoolAutoneg = 1 << 6
+
+func getLinkInfo(link netlink.Link) network.LinkInfo {
+	attrs := link.Attrs()
+	info := network.LinkInfo{MTU: attrs.MTU}
+
+	carrier, _ := os.ReadFile(filepath.Join("/sys/class/net", attrs.Name, "carrier"))
+	info.Carrier = strings.TrimSpace(string(carrier)) == "1"
+
+	var cmd ethtool.EthtoolCmd
+	speed, err := cmd.CmdGet(attrs.Name)
+	if err != nil {
+		// Not an ethernet device (Wi-Fi, VLAN, tunnel): no media to pick.
+		return info
+	}
+
+	if info.Carrier && speed < ethtoolSpeedMax {
+		info.Speed = int(speed)
+		switch cmd.Duplex {
+		case ethtoolDuplexFull:
+			info.Duplex = "full"
+		case ethtoolDuplexHalf:
+			info.Duplex = "half"
+		}
+	}
+
+	if cmd.Autoneg != 0 {
+		info.Media = "autoselect"
+	} else {
+		info.Media = fixedMedia(int(speed), cmd.Duplex)
+	}
+	return info
+}
+
+func fixedMedia(speed int, duplex uint8) string {
+	want := "<half-duplex>"
+	if duplex == ethtoolDuplexFull {
+		want = "<full-duplex>"
+	}
+	for _, m := range ethtoolModes {
+		subtype, _ := network.ParseMedia(m.media)
+		if network.MediaSpeed(subtype) == speed && strings.HasSuffix(m.media, want) {
+			return m.media
+		}
+	}
+	return ""
+}
+
+func setMTU(device string, mtu int) error {
+	link, err := netlink.LinkByName(device)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	if err := netlink.LinkSetMTU(link, mtu); err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
+
+// setMedia turns autonegotiation on for "autoselect", otherwise forces
+// the speed and duplex of the media.
+func setMedia(device, media string) error {
+	var cmd ethtool.EthtoolCmd
+	if _, err := cmd.CmdGet(device); err != nil {
+		return errors.New(parseLinkError(err))
+	}
+
+	subtype, options := network.ParseMedia(media)
+	if subtype == "autoselect" {
+		cmd.Autoneg = 1
+		cmd.Advertising = cmd.Supported
+	} else {
+		speed := network.MediaSpeed(subtype)
+		if speed == 0 {
+			return fmt.Errorf("Invalid configuration: media: %q has no speed", media)
+		}
+		cmd.Autoneg = 0
+		cmd.Speed, cmd.Speed_hi = uint16(speed), uint16(speed>>16)
+		cmd.Duplex = ethtoolDuplexHalf
+		if network.MediaDuplex(options) == "full" {
+			cmd.Duplex = ethtoolDuplexFull
+		}
+	}
+
+	if _, err := cmd.CmdSet(device); err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
+
+func mediaOptions(device string) ([]string, error) {
+	var cmd ethtool.EthtoolCmd
+	if _, err := cmd.CmdGet(device); err != nil {
+		return nil, err
+	}
+
+	options := []string{}
+	if cmd.Supported&ethtoolAutoneg != 0 {
+		options = append(options, "autoselect")
+	}
+	for _, m := range ethtoolModes {
+		if cmd.Supported&m.bit != 0 {
+			options = append(options, m.media)
+		}
+	}
+	return options, nil
+}
+
+// setServiceEnabled switches the link, services are links on Linux.
+func setServiceEnabled(serviceName string, enabled bool) error {
+	return setInterfaceEnabled(serviceName, enabled)
+}
+
+func setInterfaceEnabled(device string, enabled bool) error {
+	link, err := netlink.LinkByName(device)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+
+	if enabled {
+		err = netlink.LinkSetUp(link)
+	} else {
+		err = netlink.LinkSetDown(link)
+	}
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"errors"
+	"macbox/pkg/network"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func setMTU(device string, mtu int) error {
+	return networksetup("-setMTU", device, strconv.Itoa(mtu))
+}
+
+// setMedia runs `networksetup -setmedia en0 100baseTX full-duplex`.
+func setMedia(device, media string) error {
+	subtype, options := network.ParseMedia(media)
+	return networksetup(append([]string{"-setmedia", device, subtype}, options...)...)
+}
+
+// mediaOptions parses `networksetup -listValidMedia`, one media per line:
+//
+//	autoselect
+//	100baseTX <full-duplex>
+func mediaOptions(device string) ([]string, error) {
+	out, err := exec.Command("networksetup", "-listValidMedia", device).CombinedOutput()
+	if err != nil {
+		return nil, errors.New(parseNetworkError(out, err))
+	}
+
+	options := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "**") {
+			continue
+		}
+		options = append(options, line)
+	}
+	return options, nil
+}
+
+func setServiceEnabled(serviceName string, enabled bool) error {
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	return networksetup("-setnetworkserviceenabled", serviceName, state)
+}
+
+func setInterfaceEnabled(device string, enabled bool) error {
+	state := "down"
+	if enabled {
+		state = "up"
+	}
+	out, err := exec.Command("ifconfig", device, state).CombinedOutput()
+	if err != nil {
+		return errors.New(parseNetworkError(out, err))
+	}
+	return nil
+}
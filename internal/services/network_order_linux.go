@@ -0,0 +1,123 @@
+//go:build linux
+
+package services
+
+import (
+	"errors"
+	"macbox/pkg/network"
+	"slices"
+	"sort"
+
+	"github.com/vishvananda/netlink"
+)
+
+// Linux has no service order, the default route with the lowest metric
+// wins. The order is read from and written to those metrics.
+const (
+	orderMetricBase = 100
+	orderMetricStep = 10
+)
+
+// applyRouteOrder numbers the links that have a default route by its
+// metric. Links without one stay unordered.
+func applyRouteOrder(result []network.HardwareInterface, links []netlink.Link, routes []netlink.Route) {
+	metrics := make(map[string]int)
+	for _, link := range links {
+		if metric, ok := defaultRouteMetric(link.Attrs().Index, routes); ok {
+			metrics[link.Attrs().Name] = metric
+		}
+	}
+
+	ranked := make([]string, 0, len(metrics))
+	for dev := range metrics {
+		ranked = append(ranked, dev)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if metrics[ranked[i]] != metrics[ranked[j]] {
+			return metrics[ranked[i]] < metrics[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	for i := range result {
+		for j := range result[i].LogicInterfaces {
+			li := &result[i].LogicInterfaces[j]
+			li.Order = slices.Index(ranked, li.Device) + 1
+		}
+	}
+}
+
+func defaultRouteMetric(linkIndex int, routes []netlink.Route) (int, bool) {
+	best, found := 0, false
+	for _, r := range routes {
+		if r.LinkIndex == linkIndex && r.Gw != nil && (r.Dst == nil || r.Dst.IP.IsUnspecified()) {
+			if !found || r.Priority < best {
+				best, found = r.Priority, true
+			}
+		}
+	}
+	return best, found
+}
+
+// setServiceOrder rewrites the default route metrics so names come first,
+// then the other links with a default route in their current order. A
+// DHCP client may put its own metric back on the next renew.
+func setServiceOrder(names []string) error {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+
+	var current []network.HardwareInterface
+	for _, link := range links {
+		current = append(current, network.HardwareInterface{
+			LogicInterfaces: []network.LogicInterface{{Device: link.Attrs().Name}},
+		})
+	}
+	applyRouteOrder(current, links, routes)
+	sort.SliceStable(current, func(i, j int) bool {
+		return current[i].LogicInterfaces[0].Order < current[j].LogicInterfaces[0].Order
+	})
+
+	order := slices.Clone(names)
+	for _, hw := range current {
+		if dev := hw.LogicInterfaces[0].Device; hw.LogicInterfaces[0].Order > 0 && !slices.Contains(names, dev) {
+			order = append(order, dev)
+		}
+	}
+
+	for i, dev := range order {
+		link, err := netlink.LinkByName(dev)
+		if err != nil {
+			return errors.New(parseLinkError(err))
+		}
+		if err := setDefaultRouteMetric(link, routes, orderMetricBase+i*orderMetricStep); err != nil {
+			return errors.New(parseLinkError(err))
+		}
+	}
+	return nil
+}
+
+// setDefaultRouteMetric re-adds the default routes of link with metric.
+// The metric is part of the route key, so the old route has to go.
+func setDefaultRouteMetric(link netlink.Link, routes []netlink.Route, metric int) error {
+	for _, r := range routes {
+		if r.LinkIndex != link.Attrs().Index || r.Gw == nil || (r.Dst != nil && !r.Dst.IP.IsUnspecified()) || r.Priority == metric {
+			continue
+		}
+
+		moved := r
+		moved.Priority = metric
+		if err := netlink.RouteAdd(&moved); err != nil {
+			return err
+		}
+		if err := netlink.RouteDel(&r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
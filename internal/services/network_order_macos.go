@@ -0,0 +1,54 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// setServiceOrder puts names first. -ordernetworkservices wants every
+// service, including those without a device (VPNs) that the interface
+// tree leaves out, so the full list is read fresh.
+func setServiceOrder(names []string) error {
+	current, err := listServiceOrder()
+	if err != nil {
+		return err
+	}
+
+	order := slices.Clone(names)
+	for _, name := range current {
+		if !slices.Contains(names, name) {
+			order = append(order, name)
+		}
+	}
+	return networksetup(append([]string{"-ordernetworkservices"}, order...)...)
+}
+
+// listServiceOrder returns the service names of -listnetworkserviceorder:
+//
+//	(1) Wi-Fi
+//	(Hardware Port: Wi-Fi, Device: en0)
+//	(*) Thunderbolt Bridge
+func listServiceOrder() ([]string, error) {
+	out, err := exec.Command("networksetup", "-listnetworkserviceorder").CombinedOutput()
+	if err != nil {
+		return nil, errors.New(parseNetworkError(out, err))
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "(") || strings.HasPrefix(line, "(Hardware Port:") {
+			continue
+		}
+		if _, name, ok := strings.Cut(line, ") "); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
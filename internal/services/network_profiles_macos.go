@@ -0,0 +1,96 @@
+//go:build darwin
+
+package services
+
+import "macbox/pkg/network"
+
+func (ns *NetworkService) profileTx(profile network.Profile) (*networkTx, string) {
+	if err := ns.guard.busy(); err != nil {
+		return nil, err.Error()
+	}
+	if errs := profile.Validate(); len(errs) > 0 {
+		return nil, errs.Error()
+	}
+
+	live := ns.liveServices()
+	tx := &networkTx{}
+
+	for _, change := range network.DiffProfile(profile, live) {
+		switch change.Action {
+		case "create":
+			want := findProfileService(profile.Services, change.HardwarePort, change.Service)
+			tx.add("create "+want.Name,
+				func() error { return networksetup("-createnetworkservice", want.Name, want.HardwarePort) },
+				func() error { return networksetup("-removenetworkservice", want.Name) },
+			)
+			// Undoing the create removes the service, nothing else to revert.
+			addServiceSteps(tx, want, network.ProfileService{}, false)
+
+		case "update":
+			want := findProfileService(profile.Services, change.HardwarePort, change.Service)
+			have := findProfileService(live, change.HardwarePort, change.Service)
+			addServiceSteps(tx, want, have, true)
+
+		case "remove":
+			have := findProfileService(live, change.HardwarePort, change.Service)
+			tx.add("remove "+have.Name,
+				func() error { return networksetup("-removenetworkservice", have.Name) },
+				func() error {
+					if err := networksetup("-createnetworkservice", have.Name, have.HardwarePort); err != nil {
+						return err
+					}
+					if err := restoreIPv4(have.Name, toLogicInterface(have)); err != nil {
+						return err
+					}
+					return setDNSServers(have.Name, have.DNS)
+				},
+			)
+		}
+	}
+
+	return tx, ""
+}
+
+// addServiceSteps queues the addressing and DNS changes from have to want.
+// With revert set, each step restores the have state on rollback.
+func addServiceSteps(tx *networkTx, want, have network.ProfileService, revert bool) {
+	name := want.Name
+
+	undo := func(fn func() error) func() error {
+		if !revert {
+			return nil
+		}
+		return fn
+	}
+
+	switch want.Method {
+	case "DHCP":
+		tx.add("set DHCP on "+name,
+			func() error { return networksetup("-setdhcp", name) },
+			undo(func() error { return restoreIPv4(name, toLogicInterface(have)) }),
+		)
+	case "Manual":
+		tx.add("set manual address on "+name,
+			func() error { return networksetup("-setmanual", name, want.IP, want.Mask, want.Gateway) },
+			undo(func() error { return restoreIPv4(name, toLogicInterface(have)) }),
+		)
+	}
+
+	if want.DNS != nil {
+		tx.add("set DNS on "+name,
+			func() error { return setDNSServers(name, want.DNS) },
+			undo(func() error { return setDNSServers(name, have.DNS) }),
+		)
+	}
+}
+
+func toLogicInterface(s network.ProfileService) network.LogicInterface {
+	return network.LogicInterface{
+		ID:      s.Name,
+		Name:    s.Name,
+		IP:      s.IP,
+		Mask:    s.Mask,
+		Gateway: s.Gateway,
+		Method:  s.Method,
+	}
+}
@@ -0,0 +1,96 @@
+//go:build linux
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"macbox/pkg/network"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+var errPersistentRoutesOnLinux = errors.New("Not supported on Linux: persistent routes belong to the distribution's network configuration")
+
+func listRoutes() ([]network.Route, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, errors.New(parseLinkError(err))
+	}
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return nil, errors.New(parseLinkError(err))
+	}
+
+	result := []network.Route{}
+	for _, link := range links {
+		result = append(result, staticRoutes(link.Attrs(), routes)...)
+	}
+	return result, nil
+}
+
+// staticRoutes picks the routes via a gateway on one link, leaving out
+// the default route which belongs to the service addressing.
+func staticRoutes(attrs *netlink.LinkAttrs, routes []netlink.Route) []network.Route {
+	result := []network.Route{}
+	for _, r := range routes {
+		if r.LinkIndex != attrs.Index || r.Gw == nil || r.Dst == nil || r.Dst.IP.IsUnspecified() {
+			continue
+		}
+		result = append(result, network.Route{
+			Destination: r.Dst.IP.String(),
+			Mask:        net.IP(r.Dst.Mask).String(),
+			Gateway:     r.Gw.String(),
+			Device:      attrs.Name,
+		})
+	}
+	return result
+}
+
+func addRoute(serviceName string, route network.Route) error {
+	if route.Persistent {
+		return errPersistentRoutesOnLinux
+	}
+
+	nlRoute, err := toNetlinkRoute(route)
+	if err != nil {
+		return err
+	}
+	if err := netlink.RouteAdd(nlRoute); err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
+
+func removeRoute(serviceName string, route network.Route) error {
+	nlRoute, err := toNetlinkRoute(route)
+	if err != nil {
+		return err
+	}
+	// Any protocol: the route may come from someone else.
+	nlRoute.Protocol = 0
+	if err := netlink.RouteDel(nlRoute); err != nil {
+		if errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("Route to %s/%s via %s not found", route.Destination, route.Mask, route.Gateway)
+		}
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
+
+func toNetlinkRoute(route network.Route) (*netlink.Route, error) {
+	link, err := netlink.LinkByName(route.Device)
+	if err != nil {
+		return nil, errors.New(parseLinkError(err))
+	}
+
+	bits, _, _ := network.ParseMask(route.Mask)
+	return &netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.ParseIP(route.Destination).To4(), Mask: net.CIDRMask(bits, 32)},
+		Gw:        net.ParseIP(route.Gateway),
+		Protocol:  unix.RTPROT_STATIC,
+	}, nil
+}
@@ -0,0 +1,154 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"macbox/pkg/network"
+	"net/netip"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Persistent routes are the "additional routes" of a service, stored by
+// configd and set up whenever the service comes up. Temporary routes go
+// straight into the routing table with route(8).
+
+// getAdditionalRoutes parses -getadditionalroutes, one route per line:
+//
+//	10.10.0.0 255.255.0.0 192.168.1.5
+func getAdditionalRoutes(serviceName, deviceID string) []network.Route {
+	routes := []network.Route{}
+
+	out, err := exec.Command("networksetup", "-getadditionalroutes", serviceName).Output()
+	if err != nil {
+		return routes
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		r := network.Route{Destination: fields[0], Mask: fields[1], Gateway: fields[2], Device: deviceID, Persistent: true}
+		if len(r.Validate()) == 0 {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// setAdditionalRoutes replaces the whole list, no routes clears it.
+func setAdditionalRoutes(serviceName string, routes []network.Route) error {
+	args := []string{"-setadditionalroutes", serviceName}
+	for _, r := range routes {
+		args = append(args, r.Destination, r.Mask, r.Gateway)
+	}
+	return networksetup(args...)
+}
+
+func addRoute(serviceName string, route network.Route) error {
+	if !route.Persistent {
+		return routeCommand("add", route)
+	}
+
+	routes := getAdditionalRoutes(serviceName, route.Device)
+	if slices.ContainsFunc(routes, route.Same) {
+		return nil
+	}
+	return setAdditionalRoutes(serviceName, append(routes, route))
+}
+
+func removeRoute(serviceName string, route network.Route) error {
+	if !route.Persistent {
+		return routeCommand("delete", route)
+	}
+
+	routes := getAdditionalRoutes(serviceName, route.Device)
+	kept := slices.DeleteFunc(slices.Clone(routes), route.Same)
+	if len(kept) == len(routes) {
+		return fmt.Errorf("Route to %s/%s via %s not found", route.Destination, route.Mask, route.Gateway)
+	}
+	return setAdditionalRoutes(serviceName, kept)
+}
+
+// routeCommand runs `route -n add -net 10.10.0.0 -netmask 255.255.0.0 192.168.1.5`.
+func routeCommand(action string, r network.Route) error {
+	out, err := exec.Command("route", "-n", action, "-net", r.Destination, "-netmask", r.Mask, r.Gateway).CombinedOutput()
+	if err != nil {
+		return errors.New(parseNetworkError(out, err))
+	}
+	return nil
+}
+
+// listRoutes parses the IPv4 table of `netstat -rn -f inet` and keeps the
+// static routes via a gateway:
+//
+//	Destination        Gateway            Flags           Netif Expire
+//	default            192.168.1.1        UGScg             en0
+//	10.10/16           192.168.1.5        UGSc              en0
+func listRoutes() ([]network.Route, error) {
+	out, err := exec.Command("netstat", "-rn", "-f", "inet").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []network.Route{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] == "default" {
+			continue
+		}
+		flags := fields[2]
+		if !strings.Contains(flags, "G") || !strings.Contains(flags, "S") {
+			continue
+		}
+
+		dst, bits, ok := parseNetstatDestination(fields[0])
+		gw, err := netip.ParseAddr(fields[1])
+		if !ok || err != nil || !gw.Is4() {
+			continue
+		}
+		routes = append(routes, network.Route{
+			Destination: dst,
+			Mask:        network.MaskString(bits),
+			Gateway:     gw.String(),
+			Device:      fields[3],
+		})
+	}
+	return routes, nil
+}
+
+// parseNetstatDestination expands the short forms netstat prints:
+// "10.10/16" is 10.10.0.0/16 and "192.168.1" without a length is /24.
+func parseNetstatDestination(s string) (string, int, bool) {
+	addr, length, hasLength := strings.Cut(s, "/")
+	octets := strings.Split(addr, ".")
+	if len(octets) > 4 {
+		return "", 0, false
+	}
+
+	bits := 8 * len(octets)
+	if hasLength {
+		n, err := strconv.Atoi(length)
+		if err != nil || n < 1 || n > 32 {
+			return "", 0, false
+		}
+		bits = n
+	}
+
+	for len(octets) < 4 {
+		octets = append(octets, "0")
+	}
+	ip, err := netip.ParseAddr(strings.Join(octets, "."))
+	if err != nil || !ip.Is4() {
+		return "", 0, false
+	}
+	return ip.String(), bits, true
+}
@@ -6,9 +6,41 @@ import (
 )
 
 type INetworkService interface {
+	SetContext(ctx context.Context)
 	StartLiveLoop(ctx context.Context)
+	GetInterfaces() []network.HardwareInterface
 
 	CreateInterface(hardwarePortName string, newServiceName string) string
 	DeleteInterface(serviceName string) string
 	UpdateInterface(data network.UpdatePayload) string
+	ValidateUpdate(data network.UpdatePayload) []network.FieldError
+
+	AddAlias(serviceName, ip, mask string) string
+	RemoveAlias(serviceName, ip string) string
+
+	ListRoutes() []network.Route
+	AddRoute(serviceName string, route network.Route) string
+	RemoveRoute(serviceName string, route network.Route) string
+
+	SetServiceEnabled(serviceName string, enabled bool) string
+	SetInterfaceEnabled(device string, enabled bool) string
+	SetServiceOrder(names []string) string
+
+	SetMTU(device string, mtu int) string
+	SetMedia(device, media string) string
+	GetMediaOptions(device string) []string
+
+	ListVLANs() []network.VLAN
+	CreateVLAN(vlan network.VLAN) string
+	DeleteVLAN(name string) string
+
+	CaptureProfile(name string, ports []string) network.Profile
+	DiffProfile(profile network.Profile) []network.ProfileChange
+	ApplyProfile(profile network.Profile) string
+
+	UpdateInterfaceWithConfirm(data network.UpdatePayload, opts network.ConfirmOptions) string
+	ApplyProfileWithConfirm(profile network.Profile, opts network.ConfirmOptions) string
+	ConfirmChange() string
+	RevertChange() string
+	GetPendingChange() *network.PendingChange
 }
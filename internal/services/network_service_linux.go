@@ -0,0 +1,439 @@
+//go:build linux
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"macbox/pkg/network"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// On Linux there is no separate service layer: every link is one hardware
+// interface with a single logic interface named after the device.
+
+var errNoServicesOnLinux = errors.New("Not supported on Linux: services map one to one to devices")
+
+func (ns *NetworkService) CreateInterface(hardwarePortName string, newServiceName string) string {
+	return errNoServicesOnLinux.Error()
+}
+
+func (ns *NetworkService) DeleteInterface(serviceName string) string {
+	return errNoServicesOnLinux.Error()
+}
+
+func (ns *NetworkService) checkInterfaces() []network.HardwareInterface {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return []network.HardwareInterface{}
+	}
+	routes, _ := netlink.RouteList(nil, netlink.FAMILY_V4)
+
+	result := make([]network.HardwareInterface, 0, len(links))
+	for _, link := range links {
+		attrs := link.Attrs()
+		if attrs.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		hw := network.HardwareInterface{
+			Name:            hardwarePortName(link),
+			Device:          attrs.Name,
+			Mac:             attrs.HardwareAddr.String(),
+			IsActive:        attrs.OperState == netlink.OperUp,
+			Enabled:         attrs.Flags&net.FlagUp != 0,
+			Link:            getLinkInfo(link),
+			LogicInterfaces: []network.LogicInterface{getLinkNetworkInfo(link, routes)},
+		}
+		if vlan, ok := link.(*netlink.Vlan); ok {
+			hw.Parent, hw.VLANID = linkName(links, attrs.ParentIndex), vlan.VlanId
+		}
+		result = append(result, hw)
+	}
+
+	applyRouteOrder(result, links, routes)
+
+	sortHardware(result)
+
+	return result
+}
+
+// hardwarePortName mimics the macOS hardware port names the UI knows.
+func hardwarePortName(link netlink.Link) string {
+	name := link.Attrs().Name
+	if _, err := os.Stat(filepath.Join("/sys/class/net", name, "wireless")); err == nil {
+		return "Wi-Fi"
+	}
+	switch t := link.Type(); t {
+	case "device", "":
+		return "Ethernet"
+	case "vlan":
+		// Like on macOS, where the port carries the user defined name.
+		return name
+	default:
+		return strings.ToUpper(t[:1]) + t[1:]
+	}
+}
+
+func getLinkNetworkInfo(link netlink.Link, routes []netlink.Route) network.LogicInterface {
+	attrs := link.Attrs()
+	info := network.LogicInterface{
+		ID:      attrs.Name,
+		Name:    attrs.Name,
+		Device:  attrs.Name,
+		Method:  "Auto/Other",
+		Enabled: attrs.Flags&net.FlagUp != 0,
+	}
+
+	addrs, _ := netlink.AddrList(link, netlink.FAMILY_V4)
+	info.Aliases = []network.IPAddress{}
+	for _, a := range addrs[min(1, len(addrs)):] {
+		info.Aliases = append(info.Aliases, network.IPAddress{IP: a.IP.String(), Mask: net.IP(a.Mask).String()})
+	}
+	if len(addrs) > 0 {
+		addr := addrs[0]
+		info.IP = addr.IP.String()
+		info.Mask = net.IP(addr.Mask).String()
+
+		// Addresses from a DHCP client carry a lifetime, static ones are permanent.
+		if addr.Flags&unix.IFA_F_PERMANENT != 0 {
+			info.Method = "Manual"
+		} else {
+			info.Method = "DHCP"
+		}
+	}
+
+	if gw := defaultGateway(attrs.Index, routes); gw != nil {
+		info.Gateway = gw.String()
+	}
+
+	info.DNS, info.SearchDomains = getLinkDNS(attrs.Name)
+	info.IPv6 = getLinkIPv6(link)
+	info.Routes = staticRoutes(attrs, routes)
+
+	return info
+}
+
+func defaultGateway(linkIndex int, routes []netlink.Route) net.IP {
+	for _, r := range routes {
+		if r.LinkIndex == linkIndex && r.Gw != nil && (r.Dst == nil || r.Dst.IP.IsUnspecified()) {
+			return r.Gw
+		}
+	}
+	return nil
+}
+
+// updateTx validates the payload and builds the transaction for it.
+func (ns *NetworkService) updateTx(data network.UpdatePayload) (*networkTx, string) {
+	if err := ns.guard.busy(); err != nil {
+		return nil, err.Error()
+	}
+	if errs := data.Validate(ns.interfaces()); len(errs) > 0 {
+		return nil, errs.Error()
+	}
+	if data.NewName != "" && data.NewName != data.OldName {
+		return nil, "Not supported on Linux: services are named after their device"
+	}
+
+	tx := &networkTx{}
+	if err := addLinkSteps(tx, data); err != nil {
+		return nil, parseLinkError(err)
+	}
+	return tx, ""
+}
+
+func (ns *NetworkService) profileTx(profile network.Profile) (*networkTx, string) {
+	if err := ns.guard.busy(); err != nil {
+		return nil, err.Error()
+	}
+	if errs := profile.Validate(); len(errs) > 0 {
+		return nil, errs.Error()
+	}
+
+	tx := &networkTx{}
+	for _, change := range network.DiffProfile(profile, ns.liveServices()) {
+		if change.Action != "update" {
+			return nil, fmt.Sprintf("Cannot %s %s: %v", change.Action, change.Service, errNoServicesOnLinux)
+		}
+
+		want := findProfileService(profile.Services, change.HardwarePort, change.Service)
+		err := addLinkSteps(tx, network.UpdatePayload{
+			OldName: want.Name,
+			Method:  want.Method,
+			IP:      want.IP,
+			Mask:    want.Mask,
+			Gateway: want.Gateway,
+			DNS:     want.DNS,
+		})
+		if err != nil {
+			return nil, parseLinkError(err)
+		}
+	}
+	return tx, ""
+}
+
+// addLinkSteps queues the changes of data for one link, each step able to
+// restore what the link has right now.
+func addLinkSteps(tx *networkTx, data network.UpdatePayload) error {
+	dev := data.OldName
+	link, err := netlink.LinkByName(dev)
+	if err != nil {
+		return err
+	}
+
+	prevAddrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return err
+	}
+	routes, _ := netlink.RouteList(link, netlink.FAMILY_V4)
+	prevGateway := defaultGateway(link.Attrs().Index, routes)
+	prevDNS, prevDomains := getLinkDNS(dev)
+
+	restore := func() error { return restoreLinkIPv4(link, prevAddrs, prevGateway) }
+
+	switch data.Method {
+	case "DHCP":
+		switch manager := linkManager(dev); {
+		case manager == "NetworkManager":
+			// modify changes the active connection only, reapply goes
+			// back to the saved profile.
+			tx.add("set DHCP",
+				func() error {
+					return runCommand("nmcli", "device", "modify", dev, "ipv4.method", "auto", "ipv4.addresses", "", "ipv4.gateway", "")
+				},
+				func() error { return runCommand("nmcli", "device", "reapply", dev) },
+			)
+		case manager != "":
+			return &managedLinkError{dev: dev, manager: manager}
+		case dhclientRunning(dev):
+			// already on DHCP, a second client would fight the first
+		default:
+			tx.add("set DHCP",
+				func() error {
+					if err := dropStaticIPv4(link); err != nil {
+						return err
+					}
+					return runCommand("dhclient", "-1", dev)
+				},
+				func() error {
+					_ = runCommand("dhclient", "-r", dev)
+					return restore()
+				},
+			)
+		}
+	case "Manual":
+		tx.add("set manual address",
+			func() error { return setLinkIPv4(link, data.IP, data.Mask, data.Gateway) },
+			restore,
+		)
+	}
+
+	if data.IPv6 != nil {
+		if err := addLinkIPv6Steps(tx, link, *data.IPv6); err != nil {
+			return err
+		}
+	}
+
+	if data.DNS != nil {
+		tx.add("set DNS servers",
+			func() error { return setLinkDNS(dev, data.DNS) },
+			func() error { return setLinkDNS(dev, prevDNS) },
+		)
+	}
+
+	if data.SearchDomains != nil {
+		tx.add("set search domains",
+			func() error { return setLinkDomains(dev, data.SearchDomains) },
+			func() error { return setLinkDomains(dev, prevDomains) },
+		)
+	}
+
+	return nil
+}
+
+// dropStaticIPv4 removes the manual address and default route of a link
+// before a DHCP client takes it over. Aliases stay.
+func dropStaticIPv4(link netlink.Link) error {
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return err
+	}
+	if len(addrs) > 0 && addrs[0].Flags&unix.IFA_F_PERMANENT != 0 {
+		if err := netlink.AddrDel(link, &addrs[0]); err != nil {
+			return err
+		}
+	}
+
+	routes, err := netlink.RouteList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return err
+	}
+	for i, r := range routes {
+		if r.Gw != nil && (r.Dst == nil || r.Dst.IP.IsUnspecified()) {
+			if err := netlink.RouteDel(&routes[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setLinkIPv4(link netlink.Link, ip, mask, gateway string) error {
+	bits, _, _ := network.ParseMask(mask)
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP(ip).To4(), Mask: net.CIDRMask(bits, 32)}}
+
+	var gw net.IP
+	if gateway != "" {
+		gw = net.ParseIP(gateway)
+	}
+	return replaceLinkIPv4(link, []netlink.Addr{*addr}, gw)
+}
+
+func restoreLinkIPv4(link netlink.Link, addrs []netlink.Addr, gateway net.IP) error {
+	restored := make([]netlink.Addr, len(addrs))
+	for i, a := range addrs {
+		// Lifetimes of a DHCP lease cannot be put back, keep the address
+		// until the client renews it.
+		restored[i] = netlink.Addr{IPNet: a.IPNet, Label: a.Label}
+	}
+	return replaceLinkIPv4(link, restored, gateway)
+}
+
+// replaceLinkIPv4 swaps all IPv4 addresses of the link for addrs and points
+// the default route at gateway (if any).
+func replaceLinkIPv4(link netlink.Link, addrs []netlink.Addr, gateway net.IP) error {
+	current, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return err
+	}
+	for i := range current {
+		if err := netlink.AddrDel(link, &current[i]); err != nil {
+			return err
+		}
+	}
+	for i := range addrs {
+		if err := netlink.AddrAdd(link, &addrs[i]); err != nil {
+			return err
+		}
+	}
+
+	if gateway == nil {
+		return nil
+	}
+	return netlink.RouteReplace(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+		Gw:        gateway,
+	})
+}
+
+func addAlias(device, ip, mask string) error {
+	link, err := netlink.LinkByName(device)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+
+	bits, _, _ := network.ParseMask(mask)
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP(ip).To4(), Mask: net.CIDRMask(bits, 32)}}
+	if err := netlink.AddrAdd(link, addr); err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
+
+func removeAlias(device, ip string) error {
+	link, err := netlink.LinkByName(device)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	for i := range addrs {
+		if addrs[i].IP.String() == ip {
+			if err := netlink.AddrDel(link, &addrs[i]); err != nil {
+				return errors.New(parseLinkError(err))
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not assigned to %s", ip, device)
+}
+
+// managedLinkError refuses a change a network daemon would undo or fight.
+type managedLinkError struct {
+	dev     string
+	manager string
+}
+
+func (e *managedLinkError) Error() string {
+	return fmt.Sprintf("%s is managed by %s. Change it there, or mark the link as unmanaged first.", e.dev, e.manager)
+}
+
+// linkManager names the daemon that configures dev, "" if none does.
+func linkManager(dev string) string {
+	// "100 (connected)", "10 (unmanaged)"
+	out, err := exec.Command("nmcli", "-g", "GENERAL.STATE", "device", "show", dev).Output()
+	if state := strings.TrimSpace(string(out)); err == nil && state != "" && !strings.Contains(state, "unmanaged") {
+		return "NetworkManager"
+	}
+
+	// "2 eth0 ether routable configured"
+	out, err = exec.Command("networkctl", "list", "--no-legend", "--no-pager", dev).Output()
+	if fields := strings.Fields(string(out)); err == nil && len(fields) >= 5 && fields[1] == dev {
+		if setup := fields[4]; setup != "unmanaged" && setup != "pending" {
+			return "systemd-networkd"
+		}
+	}
+	return ""
+}
+
+// dhclientRunning reports whether a dhclient process serves dev.
+func dhclientRunning(dev string) bool {
+	cmdlines, _ := filepath.Glob("/proc/[0-9]*/cmdline")
+	for _, path := range cmdlines {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		args := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+		if filepath.Base(args[0]) == "dhclient" && slices.Contains(args[1:], dev) {
+			return true
+		}
+	}
+	return false
+}
+
+func runCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("%s: %s", name, msg)
+	}
+	return nil
+}
+
+func parseLinkError(err error) string {
+	switch {
+	case errors.Is(err, unix.EPERM), errors.Is(err, unix.EACCES):
+		return "Permission Denied: Please run the application with sudo."
+	case errors.As(err, new(*managedLinkError)):
+		return err.Error()
+	case errors.As(err, new(netlink.LinkNotFoundError)):
+		return "Service or Device not found. It might have been deleted."
+	}
+	return "System Error: " + err.Error()
+}
@@ -5,38 +5,16 @@ package services
 import (
 	"bufio"
 	"bytes"
-	"context"
+	"errors"
+	"fmt"
 	"macbox/pkg/network"
 	"os/exec"
 	"regexp"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-type NetworkService struct {
-}
-
-func NewNetworkService() *NetworkService {
-	return &NetworkService{}
-}
-
-func (ns *NetworkService) StartLiveLoop(ctx context.Context) {
-	tickerCheckInterfaces := time.NewTicker(1 * time.Second)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-tickerCheckInterfaces.C:
-			interfaces := ns.checkInterfaces()
-			runtime.EventsEmit(ctx, "network-update", interfaces)
-		}
-	}
-}
-
 func (ns *NetworkService) CreateInterface(hardwarePortName string, newServiceName string) string {
 	cmd := exec.Command("networksetup", "-createnetworkservice", newServiceName, hardwarePortName)
 	out, err := cmd.CombinedOutput()
@@ -55,32 +33,123 @@ func (ns *NetworkService) DeleteInterface(serviceName string) string {
 	return ""
 }
 
-func (ns *NetworkService) UpdateInterface(data network.UpdatePayload) string {
-	var err error
+// updateTx validates the payload and builds the transaction for it.
+func (ns *NetworkService) updateTx(data network.UpdatePayload) (*networkTx, string) {
+	if err := ns.guard.busy(); err != nil {
+		return nil, err.Error()
+	}
+	if errs := data.Validate(ns.interfaces()); len(errs) > 0 {
+		return nil, errs.Error()
+	}
+
+	// Read the service fresh rather than from the snapshot, the rollback
+	// has to restore exactly what is configured right now.
+	prev := getServiceNetworkInfo(data.OldName, ns.deviceOf(data.OldName))
 
+	tx := &networkTx{}
 	currentName := data.OldName
+
 	if data.NewName != "" && data.NewName != data.OldName {
-		cmd := exec.Command("networksetup", "-renamenetworkservice", data.OldName, data.NewName)
-		if out, e := cmd.CombinedOutput(); e != nil {
-			return parseNetworkError(out, err)
-		}
-		currentName = data.NewName
+		oldName, newName := data.OldName, data.NewName
+		tx.add("rename",
+			func() error { return networksetup("-renamenetworkservice", oldName, newName) },
+			func() error { return networksetup("-renamenetworkservice", newName, oldName) },
+		)
+		currentName = newName
 	}
 
 	if data.Method == "DHCP" {
-		cmd := exec.Command("networksetup", "-setdhcp", currentName)
-		err = cmd.Run()
+		tx.add("set DHCP",
+			func() error { return networksetup("-setdhcp", currentName) },
+			func() error { return restoreIPv4(currentName, prev) },
+		)
 	} else {
-		cmd := exec.Command("networksetup", "-setmanual", currentName, data.IP, data.Mask, data.Gateway)
-		if out, e := cmd.CombinedOutput(); e != nil {
-			return parseNetworkError(out, err)
+		tx.add("set manual address",
+			func() error { return networksetup("-setmanual", currentName, data.IP, data.Mask, data.Gateway) },
+			func() error { return restoreIPv4(currentName, prev) },
+		)
+	}
+
+	if data.IPv6 != nil {
+		v6 := *data.IPv6
+		tx.add("set IPv6",
+			func() error { return setIPv6(currentName, v6) },
+			func() error { return restoreIPv6(currentName, prev.IPv6) },
+		)
+	}
+
+	if data.DNS != nil {
+		tx.add("set DNS servers",
+			func() error { return setDNSServers(currentName, data.DNS) },
+			func() error { return setDNSServers(currentName, prev.DNS) },
+		)
+	}
+
+	if data.SearchDomains != nil {
+		tx.add("set search domains",
+			func() error { return setSearchDomains(currentName, data.SearchDomains) },
+			func() error { return setSearchDomains(currentName, prev.SearchDomains) },
+		)
+	}
+
+	return tx, ""
+}
+
+// restoreIPv4 puts back the addressing a service had in prev.
+func restoreIPv4(serviceName string, prev network.LogicInterface) error {
+	switch prev.Method {
+	case "DHCP":
+		return networksetup("-setdhcp", serviceName)
+	case "Manual":
+		return networksetup("-setmanual", serviceName, prev.IP, prev.Mask, prev.Gateway)
+	}
+	// BOOTP, Off and friends are not told apart by -getinfo, so there is
+	// no telling which call would restore them.
+	return fmt.Errorf("%s used %s addressing, which cannot be restored automatically", serviceName, prev.Method)
+}
+
+func setIPv6(serviceName string, v6 network.IPv6Update) error {
+	switch v6.Method {
+	case "Automatic":
+		return networksetup("-setv6automatic", serviceName)
+	case "Manual":
+		router := v6.Router
+		if router == "" {
+			router = "none"
 		}
+		return networksetup("-setv6manual", serviceName, v6.IP, strconv.Itoa(v6.PrefixLength), router)
+	case "Link-local only":
+		return networksetup("-setv6linklocal", serviceName)
+	case "Off":
+		return networksetup("-setv6off", serviceName)
+	}
+	return fmt.Errorf("unknown IPv6 method %q", v6.Method)
+}
+
+// restoreIPv6 puts back the IPv6 setup a service had in prev. A manual
+// setup is restored with its first address, networksetup takes one.
+func restoreIPv6(serviceName string, prev network.IPv6Config) error {
+	if prev.Method == "" || prev.Method == "Manual" && len(prev.Addresses) == 0 {
+		return nil
+	}
+	v6 := network.IPv6Update{Method: prev.Method, Router: prev.Router}
+	if prev.Method == "Manual" {
+		v6.IP, v6.PrefixLength = prev.Addresses[0].IP, prev.Addresses[0].PrefixLength
 	}
+	return setIPv6(serviceName, v6)
+}
 
+// networksetup runs one networksetup command. It exits 0 on some bad
+// input and only prints "** Error", so the output is checked as well.
+func networksetup(args ...string) error {
+	out, err := exec.Command("networksetup", args...).CombinedOutput()
+	if err == nil && bytes.Contains(out, []byte("** Error")) {
+		err = errors.New("networksetup reported an error")
+	}
 	if err != nil {
-		return err.Error()
+		return errors.New(parseNetworkError(out, err))
 	}
-	return ""
+	return nil
 }
 
 func (ns *NetworkService) checkInterfaces() []network.HardwareInterface {
@@ -93,8 +162,11 @@ func (ns *NetworkService) checkInterfaces() []network.HardwareInterface {
 	output := string(outputBytes)
 
 	hwMap := make(map[string]*network.HardwareInterface)
+	deviceAddrs := make(map[string]ifconfigInfo)
 
 	var currentServiceName string
+	order := 0
+	enabled := false
 
 	scanner := bufio.NewScanner(strings.NewReader(output))
 	for scanner.Scan() {
@@ -104,6 +176,8 @@ func (ns *NetworkService) checkInterfaces() []network.HardwareInterface {
 			parts := strings.SplitN(line, ") ", 2)
 			if len(parts) == 2 {
 				currentServiceName = parts[1]
+				order++
+				enabled = parts[0] != "(*" // "(*) Name" marks a disabled service
 			}
 			continue
 		}
@@ -117,13 +191,19 @@ func (ns *NetworkService) checkInterfaces() []network.HardwareInterface {
 				deviceID := matches[2]
 
 				logicIface := getServiceNetworkInfo(currentServiceName, deviceID)
+				logicIface.Order = order
+				logicIface.Enabled = enabled
 
 				if _, exists := hwMap[deviceID]; !exists {
+					ifc := getIfconfigInfo(deviceID)
+					deviceAddrs[deviceID] = ifc
 					hwMap[deviceID] = &network.HardwareInterface{
 						Name:            hwPortName,
 						Device:          deviceID,
 						Mac:             macMap[deviceID],
-						IsActive:        isInterfaceActive(deviceID),
+						IsActive:        ifc.active,
+						Enabled:         ifc.up,
+						Link:            ifc.link,
 						LogicInterfaces: []network.LogicInterface{},
 					}
 				}
@@ -135,22 +215,36 @@ func (ns *NetworkService) checkInterfaces() []network.HardwareInterface {
 		}
 	}
 
+	vlans, _ := listVLANs()
+	for _, v := range vlans {
+		hw, exists := hwMap[v.Device]
+		if !exists {
+			// A VLAN without a service still belongs in the tree.
+			ifc := getIfconfigInfo(v.Device)
+			hw = &network.HardwareInterface{
+				Name:            v.Name,
+				Device:          v.Device,
+				Mac:             macMap[v.Device],
+				IsActive:        ifc.active,
+				Enabled:         ifc.up,
+				Link:            ifc.link,
+				LogicInterfaces: []network.LogicInterface{},
+			}
+			hwMap[v.Device] = hw
+		}
+		hw.Parent, hw.VLANID = v.Parent, v.ID
+	}
+
+	table, _ := listRoutes()
+
 	result := make([]network.HardwareInterface, 0, len(hwMap))
 	for _, hw := range hwMap {
+		deviceRoutes := slices.DeleteFunc(slices.Clone(table), func(r network.Route) bool { return r.Device != hw.Device })
+		assignDeviceAddrs(hw.LogicInterfaces, deviceAddrs[hw.Device], deviceRoutes)
 		result = append(result, *hw)
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		if result[i].Name == "Wi-Fi" && result[j].Name != "Wi-Fi" {
-			return true
-		}
-
-		if result[i].Name != "Wi-Fi" && result[j].Name == "Wi-Fi" {
-			return false
-		}
-
-		return result[i].Device < result[j].Device
-	})
+	sortHardware(result)
 
 	return result
 }
@@ -190,6 +284,7 @@ func getServiceNetworkInfo(serviceName, deviceID string) network.LogicInterface
 		Name:   serviceName,
 		Device: deviceID,
 		Method: "Unknown",
+		IPv6:   network.IPv6Config{Addresses: []network.IPv6Address{}},
 	}
 
 	out, err := exec.Command("networksetup", "-getinfo", serviceName).Output()
@@ -220,19 +315,175 @@ func getServiceNetworkInfo(serviceName, deviceID string) network.LogicInterface
 				val = ""
 			}
 			info.Gateway = val
+		} else if strings.HasPrefix(line, "IPv6: ") {
+			info.IPv6.Method = ipv6Method(strings.TrimPrefix(line, "IPv6: "))
+		} else if strings.HasPrefix(line, "IPv6 IP address: ") {
+			if val := strings.TrimPrefix(line, "IPv6 IP address: "); val != "none" {
+				info.IPv6.Addresses = append(info.IPv6.Addresses, network.IPv6Address{IP: val})
+			}
+		} else if strings.HasPrefix(line, "IPv6 Prefix Length: ") {
+			n, _ := strconv.Atoi(strings.TrimPrefix(line, "IPv6 Prefix Length: "))
+			if len(info.IPv6.Addresses) > 0 {
+				info.IPv6.Addresses[len(info.IPv6.Addresses)-1].PrefixLength = n
+			}
+		} else if strings.HasPrefix(line, "IPv6 Router: ") {
+			if val := strings.TrimPrefix(line, "IPv6 Router: "); val != "none" {
+				info.IPv6.Router = val
+			}
 		}
 	}
 
+	info.DNS = getDNSServers(serviceName)
+	info.SearchDomains = getSearchDomains(serviceName)
+	info.Routes = getAdditionalRoutes(serviceName, deviceID)
+
 	return info
 }
 
-func isInterfaceActive(device string) bool {
+// ipv6Method maps the "IPv6:" line of -getinfo onto the names used in
+// the model.
+func ipv6Method(value string) string {
+	switch strings.ToLower(value) {
+	case "automatic":
+		return "Automatic"
+	case "manual":
+		return "Manual"
+	case "off":
+		return "Off"
+	}
+	if strings.HasPrefix(strings.ToLower(value), "link") {
+		return "Link-local only"
+	}
+	return value
+}
+
+type ifconfigInfo struct {
+	active bool
+	up     bool
+	link   network.LinkInfo
+	inet   []network.IPAddress
+	inet6  []network.IPv6Address
+}
+
+// getIfconfigInfo reports whether the device has link and lists its
+// addresses, including aliases added with `ifconfig alias` and IPv6
+// addresses from router advertisements.
+func getIfconfigInfo(device string) ifconfigInfo {
+	var info ifconfigInfo
+
 	cmd := exec.Command("ifconfig", device)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return false
+		return info
+	}
+
+	// en0: flags=8863<UP,BROADCAST,SMART,RUNNING,SIMPLEX,MULTICAST> mtu 1500
+	// inet 192.168.1.10 netmask 0xffffff00 broadcast 192.168.1.255
+	// inet6 fe80::1c8a:5eff:fe12:3456%en0 prefixlen 64 secured scopeid 0x4
+	// media: autoselect (1000baseT <full-duplex>)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[len(fields)-2] == "mtu" && info.link.MTU == 0:
+			info.link.MTU, _ = strconv.Atoi(fields[len(fields)-1])
+			info.up = strings.Contains(line, "<UP,") || strings.Contains(line, "<UP>")
+		case strings.HasPrefix(line, "media: "):
+			parseIfconfigMedia(strings.TrimPrefix(line, "media: "), &info.link)
+		case len(fields) >= 4 && fields[0] == "inet" && fields[2] == "netmask":
+			info.inet = append(info.inet, network.IPAddress{IP: fields[1], Mask: hexMaskToDotted(fields[3])})
+		case len(fields) >= 4 && fields[0] == "inet6" && fields[2] == "prefixlen":
+			ip, _, _ := strings.Cut(fields[1], "%")
+			n, _ := strconv.Atoi(fields[3])
+			info.inet6 = append(info.inet6, network.IPv6Address{IP: ip, PrefixLength: n})
+		}
+	}
+
+	info.active = strings.Contains(string(output), "status: active")
+	info.link.Carrier = info.active
+	return info
+}
+
+// parseIfconfigMedia reads "autoselect (1000baseT <full-duplex>)": the
+// selected media, then in parentheses the active one if it differs.
+func parseIfconfigMedia(value string, link *network.LinkInfo) {
+	selected, active, _ := strings.Cut(value, " (")
+	active = strings.TrimSuffix(active, ")")
+	if active == "" {
+		active = selected
+	}
+
+	link.Media = selected
+	subtype, options := network.ParseMedia(active)
+	link.Speed = network.MediaSpeed(subtype)
+	link.Duplex = network.MediaDuplex(options)
+}
+
+func hexMaskToDotted(hexMask string) string {
+	n, err := strconv.ParseUint(strings.TrimPrefix(hexMask, "0x"), 16, 32)
+	if err != nil {
+		return hexMask
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// assignDeviceAddrs hands the device addresses that are not the primary
+// address of any service to the service that owns the device's primary
+// address, or the first service if none has one. That service also gets
+// the autoconfigured IPv6 addresses, -getinfo only lists manual ones, and
+// the temporary routes out of the device.
+func assignDeviceAddrs(services []network.LogicInterface, ifc ifconfigInfo, routes []network.Route) {
+	if len(services) == 0 {
+		return
+	}
+
+	owner := 0
+	primary := make(map[string]bool)
+	for i, li := range services {
+		services[i].Aliases = []network.IPAddress{}
+		if li.IP != "" {
+			if len(primary) == 0 {
+				owner = i
+			}
+			primary[li.IP] = true
+		}
+	}
+
+	for _, a := range ifc.inet {
+		if !primary[a.IP] {
+			services[owner].Aliases = append(services[owner].Aliases, a)
+		}
+	}
+
+	if v6 := &services[owner].IPv6; v6.Method != "Manual" && v6.Method != "Off" && len(ifc.inet6) > 0 {
+		v6.Addresses = ifc.inet6
+	}
+
+	for _, r := range routes {
+		persistent := slices.ContainsFunc(services, func(li network.LogicInterface) bool {
+			return slices.ContainsFunc(li.Routes, r.Same)
+		})
+		if !persistent {
+			services[owner].Routes = append(services[owner].Routes, r)
+		}
+	}
+}
+
+func addAlias(device, ip, mask string) error {
+	out, err := exec.Command("ifconfig", device, "alias", ip, "netmask", mask).CombinedOutput()
+	if err != nil {
+		return errors.New(parseNetworkError(out, err))
+	}
+	return nil
+}
+
+func removeAlias(device, ip string) error {
+	out, err := exec.Command("ifconfig", device, "-alias", ip).CombinedOutput()
+	if err != nil {
+		return errors.New(parseNetworkError(out, err))
 	}
-	return strings.Contains(string(output), "status: active")
+	return nil
 }
 
 func parseNetworkError(output []byte, err error) string {
@@ -0,0 +1,540 @@
+//go:build darwin || linux
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"macbox/pkg/network"
+	"math"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// NetworkService reads and changes the system network configuration.
+// Everything in this file is shared, the per-OS files provide
+// checkInterfaces, updateTx, profileTx and the create/delete commands.
+type NetworkService struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	snapshot []network.HardwareInterface
+	guard    confirmGuard
+	traffic  trafficMeter
+}
+
+func NewNetworkService() *NetworkService {
+	return &NetworkService{}
+}
+
+func (ns *NetworkService) SetContext(ctx context.Context) {
+	ns.ctx = ctx
+}
+
+const (
+	// pollInterval is used when the OS change notifications are not
+	// available.
+	pollInterval = 1 * time.Second
+	// settleDelay coalesces a burst of notifications (link up, address,
+	// route) into a single read.
+	settleDelay = 250 * time.Millisecond
+)
+
+// StartLiveLoop re-reads the interface tree whenever the OS reports a
+// change and emits "network-update" with the difference to the last read.
+// Nothing is emitted while nothing changes. If notifications cannot be set
+// up, or stop, the loop falls back to polling. The traffic counters are
+// read on their own, cheaper tick and go out the same way.
+func (ns *NetworkService) StartLiveLoop(ctx context.Context) {
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	interval := watchPollInterval
+	watchDone, err := startNetworkWatch(ctx, notify)
+	if err != nil {
+		runtime.LogWarningf(ctx, "network change notifications unavailable, polling: %v", err)
+		interval = pollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	settle := time.NewTimer(0)
+	defer settle.Stop()
+
+	trafficTicker := time.NewTicker(trafficInterval)
+	defer trafficTicker.Stop()
+
+	var last []network.HardwareInterface
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-watchDone:
+			if ctx.Err() != nil {
+				return
+			}
+			runtime.LogWarning(ctx, "network change notifications stopped, polling")
+			watchDone = nil
+			ticker.Reset(pollInterval)
+		case <-changes:
+			settle.Reset(settleDelay)
+		case <-ticker.C:
+			last = ns.emitChanges(ctx, last, ns.traffic.apply(ns.refresh()))
+		case <-settle.C:
+			last = ns.emitChanges(ctx, last, ns.traffic.apply(ns.refresh()))
+		case <-trafficTicker.C:
+			if err := ns.traffic.sample(); err != nil {
+				continue
+			}
+			last = ns.emitChanges(ctx, last, ns.traffic.apply(last))
+		}
+	}
+}
+
+// emitChanges emits the difference between last, the tree of the
+// previous event, and current. Returns current.
+func (ns *NetworkService) emitChanges(ctx context.Context, last, current []network.HardwareInterface) []network.HardwareInterface {
+	if diff := network.DiffInterfaces(last, current); !diff.Empty() {
+		runtime.EventsEmit(ctx, "network-update", diff)
+	}
+	return current
+}
+
+// GetInterfaces returns the current interface tree, "network-update"
+// events carry the changes on top of it.
+func (ns *NetworkService) GetInterfaces() []network.HardwareInterface {
+	return ns.traffic.apply(ns.interfaces())
+}
+
+// refresh re-reads the interface tree and remembers it as the latest snapshot.
+func (ns *NetworkService) refresh() []network.HardwareInterface {
+	interfaces := ns.checkInterfaces()
+
+	ns.mu.Lock()
+	ns.snapshot = interfaces
+	ns.mu.Unlock()
+
+	return interfaces
+}
+
+// interfaces returns the snapshot taken by the live loop, reading the
+// system only if the loop has not run yet.
+func (ns *NetworkService) interfaces() []network.HardwareInterface {
+	ns.mu.Lock()
+	snapshot := ns.snapshot
+	ns.mu.Unlock()
+
+	if snapshot == nil {
+		return ns.refresh()
+	}
+	return snapshot
+}
+
+func (ns *NetworkService) ValidateUpdate(data network.UpdatePayload) []network.FieldError {
+	return data.Validate(ns.interfaces())
+}
+
+func (ns *NetworkService) UpdateInterface(data network.UpdatePayload) string {
+	tx, errMsg := ns.updateTx(data)
+	if errMsg != "" {
+		return errMsg
+	}
+	return errString(tx.run())
+}
+
+// UpdateInterfaceWithConfirm applies the update and reverts it unless
+// ConfirmChange is called (or opts.PingTarget answers) before the timeout.
+func (ns *NetworkService) UpdateInterfaceWithConfirm(data network.UpdatePayload, opts network.ConfirmOptions) string {
+	tx, errMsg := ns.updateTx(data)
+	if errMsg != "" {
+		return errMsg
+	}
+	p, err := ns.guard.reserve()
+	if err != nil {
+		return err.Error()
+	}
+	if err := tx.run(); err != nil {
+		ns.guard.release(p)
+		return err.Error()
+	}
+
+	ns.guard.arm(ns.ctx, p, tx, "Update "+data.OldName, opts)
+	return ""
+}
+
+func (ns *NetworkService) ConfirmChange() string {
+	return ns.guard.confirm(ns.ctx)
+}
+
+func (ns *NetworkService) RevertChange() string {
+	return ns.guard.revertNow(ns.ctx)
+}
+
+func (ns *NetworkService) GetPendingChange() *network.PendingChange {
+	return ns.guard.info()
+}
+
+// AddAlias puts an extra IPv4 address on the device of a service, e.g. to
+// reach a camera with a fixed 192.168.1.x address while staying on the
+// lab subnet.
+func (ns *NetworkService) AddAlias(serviceName, ip, mask string) string {
+	payload := network.UpdatePayload{OldName: serviceName, Method: "Manual", IP: ip, Mask: mask}
+	if errs := payload.Validate(nil); len(errs) > 0 {
+		return errs.Error()
+	}
+
+	device := ns.deviceOf(serviceName)
+	if device == "" {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(addAlias(device, payload.IP, payload.Mask))
+}
+
+func (ns *NetworkService) RemoveAlias(serviceName, ip string) string {
+	device := ns.deviceOf(serviceName)
+	if device == "" {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(removeAlias(device, ip))
+}
+
+// ListRoutes returns the static routes of the routing table, the ones
+// with a gateway other than the default route.
+func (ns *NetworkService) ListRoutes() []network.Route {
+	routes, err := listRoutes()
+	if err != nil {
+		return []network.Route{}
+	}
+	return routes
+}
+
+// AddRoute adds a route via the device of a service, e.g. to reach
+// vehicles behind a companion router.
+func (ns *NetworkService) AddRoute(serviceName string, route network.Route) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	if errs := route.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+
+	route.Device = ns.deviceOf(serviceName)
+	if route.Device == "" {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(addRoute(serviceName, route))
+}
+
+func (ns *NetworkService) RemoveRoute(serviceName string, route network.Route) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	if errs := route.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+
+	route.Device = ns.deviceOf(serviceName)
+	if route.Device == "" {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(removeRoute(serviceName, route))
+}
+
+// SetServiceEnabled turns a service off and on again without deleting
+// it. On Linux, where a service is its link, this is SetInterfaceEnabled.
+func (ns *NetworkService) SetServiceEnabled(serviceName string, enabled bool) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	if ns.deviceOf(serviceName) == "" {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(setServiceEnabled(serviceName, enabled))
+}
+
+// SetInterfaceEnabled brings a device administratively up or down.
+func (ns *NetworkService) SetInterfaceEnabled(device string, enabled bool) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	if !ns.hasDevice(device) {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(setInterfaceEnabled(device, enabled))
+}
+
+// SetServiceOrder moves the named services to the top of the service
+// order, first name first. The other services keep their relative order
+// behind them.
+func (ns *NetworkService) SetServiceOrder(names []string) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+
+	var errs network.FieldErrors
+	for i, name := range names {
+		switch {
+		case slices.Index(names, name) != i:
+			errs = append(errs, network.FieldError{Field: "order", Message: "service " + strconv.Quote(name) + " is listed twice"})
+		case ns.deviceOf(name) == "":
+			errs = append(errs, network.FieldError{Field: "order", Message: "service " + strconv.Quote(name) + " not found"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs.Error()
+	}
+	return errString(setServiceOrder(names))
+}
+
+// SetMTU changes the MTU of a device, e.g. 9000 for jumbo frames.
+func (ns *NetworkService) SetMTU(device string, mtu int) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	if errs := network.ValidateMTU(mtu); len(errs) > 0 {
+		return errs.Error()
+	}
+	if !ns.hasDevice(device) {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(setMTU(device, mtu))
+}
+
+// SetMedia selects the media of a device: "autoselect" or one of
+// GetMediaOptions, e.g. "100baseTX <full-duplex>".
+func (ns *NetworkService) SetMedia(device, media string) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	if subtype, _ := network.ParseMedia(media); subtype == "" {
+		return network.FieldErrors{{Field: "media", Message: "media is required"}}.Error()
+	}
+	if !ns.hasDevice(device) {
+		return "Service or Device not found. It might have been deleted."
+	}
+	return errString(setMedia(device, media))
+}
+
+// GetMediaOptions lists the media a device accepts, empty if it has no
+// choice (Wi-Fi, virtual devices).
+func (ns *NetworkService) GetMediaOptions(device string) []string {
+	options, err := mediaOptions(device)
+	if err != nil {
+		return []string{}
+	}
+	return options
+}
+
+func (ns *NetworkService) hasDevice(device string) bool {
+	return slices.ContainsFunc(ns.interfaces(), func(hw network.HardwareInterface) bool {
+		return hw.Device == device
+	})
+}
+
+// ServiceSubnet returns the IPv4 subnet a service is on, e.g. for a scan
+// of its neighbours.
+func (ns *NetworkService) ServiceSubnet(serviceName string) (netip.Prefix, string) {
+	for _, hw := range ns.interfaces() {
+		for _, li := range hw.LogicInterfaces {
+			if li.Name != serviceName {
+				continue
+			}
+			addr, err := netip.ParseAddr(li.IP)
+			bits, _, ok := network.ParseMask(li.Mask)
+			if err != nil || !addr.Is4() || !ok {
+				return netip.Prefix{}, fmt.Sprintf("%s has no IPv4 address.", serviceName)
+			}
+			return netip.PrefixFrom(addr, bits).Masked(), ""
+		}
+	}
+	return netip.Prefix{}, "Service or Device not found. It might have been deleted."
+}
+
+func (ns *NetworkService) ListVLANs() []network.VLAN {
+	vlans, err := listVLANs()
+	if err != nil {
+		return []network.VLAN{}
+	}
+	return vlans
+}
+
+// CreateVLAN adds a tagged interface on a hardware port. It shows up in
+// the tree as a child of the port with its own service.
+func (ns *NetworkService) CreateVLAN(vlan network.VLAN) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+	if errs := vlan.Validate(ns.interfaces()); len(errs) > 0 {
+		return errs.Error()
+	}
+	return errString(createVLAN(vlan))
+}
+
+func (ns *NetworkService) DeleteVLAN(name string) string {
+	if err := ns.guard.busy(); err != nil {
+		return err.Error()
+	}
+
+	vlans, err := listVLANs()
+	if err != nil {
+		return errString(err)
+	}
+	for _, v := range vlans {
+		if v.Name == name {
+			return errString(deleteVLAN(v))
+		}
+	}
+	return "Service or Device not found. It might have been deleted."
+}
+
+// deviceOf looks up the device of a service in the latest snapshot.
+func (ns *NetworkService) deviceOf(serviceName string) string {
+	for _, hw := range ns.interfaces() {
+		for _, li := range hw.LogicInterfaces {
+			if li.Name == serviceName {
+				return hw.Device
+			}
+		}
+	}
+	return ""
+}
+
+// CaptureProfile turns the current configuration of the given hardware
+// ports (all of them if ports is empty) into a profile.
+func (ns *NetworkService) CaptureProfile(name string, ports []string) network.Profile {
+	profile := network.Profile{
+		Name:      name,
+		Services:  []network.ProfileService{},
+		UpdatedAt: time.Now(),
+	}
+
+	for _, s := range ns.liveServices() {
+		if len(ports) == 0 || slices.Contains(ports, s.HardwarePort) {
+			profile.Services = append(profile.Services, s)
+		}
+	}
+	return profile
+}
+
+func (ns *NetworkService) DiffProfile(profile network.Profile) []network.ProfileChange {
+	return network.DiffProfile(profile, ns.liveServices())
+}
+
+// ApplyProfile brings the ports covered by the profile to the profile
+// state. All changes run in one transaction and are rolled back together.
+func (ns *NetworkService) ApplyProfile(profile network.Profile) string {
+	tx, errMsg := ns.profileTx(profile)
+	if errMsg != "" {
+		return errMsg
+	}
+	return errString(tx.run())
+}
+
+// ApplyProfileWithConfirm applies the profile and reverts it unless the
+// change is confirmed before the timeout.
+func (ns *NetworkService) ApplyProfileWithConfirm(profile network.Profile, opts network.ConfirmOptions) string {
+	tx, errMsg := ns.profileTx(profile)
+	if errMsg != "" {
+		return errMsg
+	}
+	p, err := ns.guard.reserve()
+	if err != nil {
+		return err.Error()
+	}
+	if err := tx.run(); err != nil {
+		ns.guard.release(p)
+		return err.Error()
+	}
+
+	ns.guard.arm(ns.ctx, p, tx, "Apply profile "+profile.Name, opts)
+	return ""
+}
+
+// liveServices reads the current configuration of every service, DNS
+// included, bypassing the cache of the live loop.
+func (ns *NetworkService) liveServices() []network.ProfileService {
+	resolverCache.reset()
+
+	var services []network.ProfileService
+	for _, hw := range ns.refresh() {
+		for _, li := range hw.LogicInterfaces {
+			services = append(services, network.ProfileService{
+				HardwarePort: hw.Name,
+				Name:         li.Name,
+				Method:       li.Method,
+				IP:           li.IP,
+				Mask:         li.Mask,
+				Gateway:      li.Gateway,
+				DNS:          li.DNS,
+			})
+		}
+	}
+	return services
+}
+
+func findProfileService(services []network.ProfileService, port, name string) network.ProfileService {
+	for _, s := range services {
+		if s.HardwarePort == port && s.Name == name {
+			return s
+		}
+	}
+	return network.ProfileService{HardwarePort: port, Name: name}
+}
+
+// sortHardware puts Wi-Fi first, then orders by device name.
+// sortHardware orders ports by the priority of their best service, the
+// way the system picks the primary interface. Ports without an ordered
+// service go last.
+func sortHardware(result []network.HardwareInterface) {
+	rank := func(hw network.HardwareInterface) int {
+		best := math.MaxInt
+		for _, li := range hw.LogicInterfaces {
+			if li.Order > 0 && li.Order < best {
+				best = li.Order
+			}
+		}
+		return best
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if ri, rj := rank(result[i]), rank(result[j]); ri != rj {
+			return ri < rj
+		}
+		return result[i].Device < result[j].Device
+	})
+
+	// Move VLANs right behind their parent port, by tag.
+	ordered := make([]network.HardwareInterface, 0, len(result))
+	children := make(map[string][]network.HardwareInterface)
+	devices := make(map[string]bool)
+	for _, hw := range result {
+		devices[hw.Device] = true
+	}
+	for _, hw := range result {
+		if hw.Parent != "" && devices[hw.Parent] {
+			children[hw.Parent] = append(children[hw.Parent], hw)
+		}
+	}
+	for _, hw := range result {
+		if hw.Parent != "" && devices[hw.Parent] {
+			continue
+		}
+		ordered = append(ordered, hw)
+		vlans := children[hw.Device]
+		sort.SliceStable(vlans, func(i, j int) bool { return vlans[i].VLANID < vlans[j].VLANID })
+		ordered = append(ordered, vlans...)
+	}
+	copy(result, ordered)
+}
@@ -0,0 +1,87 @@
+//go:build darwin || linux
+
+package services
+
+import (
+	"macbox/pkg/network"
+	"sync"
+	"time"
+)
+
+const (
+	// trafficInterval is how often the counters are read.
+	trafficInterval = 1 * time.Second
+	// trafficWindow is the span the throughput is averaged over.
+	trafficWindow = 5 * time.Second
+)
+
+// trafficMeter keeps the latest counters per device and computes the
+// throughput from the samples of the last trafficWindow.
+type trafficMeter struct {
+	mu      sync.Mutex
+	samples map[string][]trafficSample
+	latest  map[string]network.InterfaceStats
+}
+
+type trafficSample struct {
+	at     time.Time
+	rx, tx uint64
+}
+
+// sample reads the counters of all devices.
+func (m *trafficMeter) sample() error {
+	counters, err := readCounters()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.samples == nil {
+		m.samples = make(map[string][]trafficSample)
+	}
+	m.latest = make(map[string]network.InterfaceStats, len(counters))
+
+	for device, stats := range counters {
+		samples := m.samples[device]
+		if n := len(samples); n > 0 && (stats.RxBytes < samples[n-1].rx || stats.TxBytes < samples[n-1].tx) {
+			// Counters went back: the device was recreated or wrapped.
+			samples = nil
+		}
+
+		samples = append(samples, trafficSample{at: now, rx: stats.RxBytes, tx: stats.TxBytes})
+		for len(samples) > 2 && now.Sub(samples[0].at) > trafficWindow {
+			samples = samples[1:]
+		}
+		m.samples[device] = samples
+
+		if first := samples[0]; len(samples) > 1 {
+			seconds := now.Sub(first.at).Seconds()
+			stats.RxBps = float64(stats.RxBytes-first.rx) * 8 / seconds
+			stats.TxBps = float64(stats.TxBytes-first.tx) * 8 / seconds
+		}
+		m.latest[device] = stats
+	}
+
+	for device := range m.samples {
+		if _, ok := counters[device]; !ok {
+			delete(m.samples, device)
+		}
+	}
+	return nil
+}
+
+// apply returns a copy of interfaces with the latest stats filled in.
+func (m *trafficMeter) apply(interfaces []network.HardwareInterface) []network.HardwareInterface {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]network.HardwareInterface, len(interfaces))
+	for i, hw := range interfaces {
+		hw.Stats = m.latest[hw.Device]
+		result[i] = hw
+	}
+	return result
+}
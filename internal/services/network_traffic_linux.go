@@ -0,0 +1,53 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"macbox/pkg/network"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readCounters parses /proc/net/dev:
+//
+//	Inter-|   Receive                                                |  Transmit
+//	 face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+//	  eth0: 1234567    8910    0    0    0     0          0         0  7654321    1098    0    0    0     0       0          0
+func readCounters() (map[string]network.InterfaceStats, error) {
+	f, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := make(map[string]network.InterfaceStats)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		device, counters, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) < 12 {
+			continue
+		}
+
+		n := func(i int) uint64 {
+			v, _ := strconv.ParseUint(fields[i], 10, 64)
+			return v
+		}
+		result[strings.TrimSpace(device)] = network.InterfaceStats{
+			RxBytes:   n(0),
+			RxPackets: n(1),
+			RxErrors:  n(2),
+			RxDropped: n(3),
+			TxBytes:   n(8),
+			TxPackets: n(9),
+			TxErrors:  n(10),
+			TxDropped: n(11),
+		}
+	}
+	return result, scanner.Err()
+}
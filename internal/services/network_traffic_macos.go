@@ -0,0 +1,52 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"macbox/pkg/network"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// readCounters parses `netstat -ibnd`, using the link level row of every
+// device. Name and Address may be missing or contain spaces, so the
+// counters are taken from the end of the row:
+//
+//	Name  Mtu   Network       Address            Ipkts Ierrs     Ibytes    Opkts Oerrs     Obytes  Coll Drop
+//	en0   1500  <Link#11>   a4:83:e7:bd:cc:12  1203651     0 1456210744   581021     0  101372190     0    0
+//
+// netstat has no input drop counter, RxDropped stays 0.
+func readCounters() (map[string]network.InterfaceStats, error) {
+	out, err := exec.Command("netstat", "-ibnd").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]network.InterfaceStats)
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 11 || !strings.HasPrefix(fields[2], "<Link#") {
+			continue
+		}
+
+		counters := fields[len(fields)-8:]
+		n := func(i int) uint64 {
+			v, _ := strconv.ParseUint(counters[i], 10, 64)
+			return v
+		}
+		device := strings.TrimSuffix(fields[0], "*") // "*" marks a device that is down
+		result[device] = network.InterfaceStats{
+			RxPackets: n(0),
+			RxErrors:  n(1),
+			RxBytes:   n(2),
+			TxPackets: n(3),
+			TxErrors:  n(4),
+			TxBytes:   n(5),
+			TxDropped: n(7),
+		}
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// networkTx applies a sequence of system changes as one unit: if a step
+// fails, every step already applied is reverted in reverse order.
+type networkTx struct {
+	steps []txStep
+}
+
+type txStep struct {
+	name   string
+	apply  func() error
+	revert func() error // nil if the step has nothing to undo
+}
+
+// TxError reports which step of a transaction failed and whether the
+// rollback managed to restore the previous state.
+type TxError struct {
+	Step        string
+	Err         error
+	RollbackErr error
+}
+
+func (e *TxError) Error() string {
+	msg := fmt.Sprintf("Step %q failed: %v.", e.Step, e.Err)
+	if e.RollbackErr != nil {
+		return msg + " Rollback failed, the service may be left misconfigured: " + e.RollbackErr.Error()
+	}
+	return msg + " Previous configuration was restored."
+}
+
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+func (tx *networkTx) add(name string, apply, revert func() error) {
+	tx.steps = append(tx.steps, txStep{name: name, apply: apply, revert: revert})
+}
+
+func (tx *networkTx) run() error {
+	for i, step := range tx.steps {
+		if err := step.apply(); err != nil {
+			return &TxError{Step: step.name, Err: err, RollbackErr: tx.rollback(i)}
+		}
+	}
+	return nil
+}
+
+// revert undoes a transaction that ran successfully.
+func (tx *networkTx) revert() error {
+	return tx.rollback(len(tx.steps))
+}
+
+// rollback reverts the first n steps, newest first, and keeps going past
+// failures so as much as possible is restored.
+func (tx *networkTx) rollback(n int) error {
+	var errs []error
+	for i := n - 1; i >= 0; i-- {
+		step := tx.steps[i]
+		if step.revert == nil {
+			continue
+		}
+		if err := step.revert(); err != nil {
+			errs = append(errs, fmt.Errorf("undo %q: %w", step.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"macbox/pkg/network"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func listVLANs() ([]network.VLAN, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, errors.New(parseLinkError(err))
+	}
+
+	vlans := []network.VLAN{}
+	for _, link := range links {
+		if vlan, ok := link.(*netlink.Vlan); ok {
+			vlans = append(vlans, network.VLAN{
+				Name:   vlan.Name,
+				Device: vlan.Name,
+				Parent: linkName(links, vlan.ParentIndex),
+				ID:     vlan.VlanId,
+			})
+		}
+	}
+	return vlans, nil
+}
+
+func createVLAN(v network.VLAN) error {
+	if len(v.Name) > unix.IFNAMSIZ-1 {
+		return fmt.Errorf("Invalid configuration: name: link names are limited to %d characters on Linux", unix.IFNAMSIZ-1)
+	}
+
+	parent, err := netlink.LinkByName(v.Parent)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+
+	vlan := &netlink.Vlan{
+		LinkAttrs: netlink.LinkAttrs{Name: v.Name, ParentIndex: parent.Attrs().Index},
+		VlanId:    v.ID,
+	}
+	if err := netlink.LinkAdd(vlan); err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	if err := netlink.LinkSetUp(vlan); err != nil {
+		_ = netlink.LinkDel(vlan)
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
+
+func deleteVLAN(v network.VLAN) error {
+	link, err := netlink.LinkByName(v.Device)
+	if err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	if err := netlink.LinkDel(link); err != nil {
+		return errors.New(parseLinkError(err))
+	}
+	return nil
+}
+
+func linkName(links []netlink.Link, index int) string {
+	for _, l := range links {
+		if l.Attrs().Index == index {
+			return l.Attrs().Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"macbox/pkg/network"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// listVLANs parses `networksetup -listVLANs`:
+//
+//	VLAN User Defined Name: Lab VLAN
+//	Parent Device: en0
+//	Device ("Hardware" Port): vlan0
+//	Tag: 100
+func listVLANs() ([]network.VLAN, error) {
+	out, err := exec.Command("networksetup", "-listVLANs").CombinedOutput()
+	if err != nil {
+		return nil, err
+	}
+
+	vlans := []network.VLAN{}
+	var current *network.VLAN
+
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ": ")
+		if !ok {
+			continue
+		}
+
+		switch {
+		case key == "VLAN User Defined Name":
+			vlans = append(vlans, network.VLAN{Name: value})
+			current = &vlans[len(vlans)-1]
+		case current == nil:
+		case key == "Parent Device":
+			current.Parent = value
+		case strings.HasPrefix(key, "Device"):
+			current.Device = value
+		case key == "Tag":
+			current.ID, _ = strconv.Atoi(value)
+		}
+	}
+	return vlans, nil
+}
+
+func createVLAN(v network.VLAN) error {
+	return networksetup("-createVLAN", v.Name, v.Parent, strconv.Itoa(v.ID))
+}
+
+func deleteVLAN(v network.VLAN) error {
+	return networksetup("-deleteVLAN", v.Name, v.Parent, strconv.Itoa(v.ID))
+}
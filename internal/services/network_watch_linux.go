@@ -0,0 +1,76 @@
+//go:build linux
+
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// watchPollInterval is the safety net re-read while netlink notifications
+// work. Only the resolver configuration is not covered by them.
+const watchPollInterval = 30 * time.Second
+
+// startNetworkWatch subscribes to the netlink link, address and route
+// groups and calls notify on every message. The returned channel is
+// closed if a subscription ends before ctx does.
+func startNetworkWatch(ctx context.Context, notify func()) (<-chan struct{}, error) {
+	links := make(chan netlink.LinkUpdate)
+	addrs := make(chan netlink.AddrUpdate)
+	routes := make(chan netlink.RouteUpdate)
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	if err := netlink.LinkSubscribe(links, ctx.Done()); err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := netlink.AddrSubscribe(addrs, ctx.Done()); err != nil {
+		cancel()
+		drain(links)
+		return nil, err
+	}
+	if err := netlink.RouteSubscribe(routes, ctx.Done()); err != nil {
+		cancel()
+		drain(links)
+		drain(addrs)
+		return nil, err
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		defer func() {
+			cancel()
+			drain(links)
+			drain(addrs)
+			drain(routes)
+		}()
+		for {
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok = <-links:
+			case _, ok = <-addrs:
+			case _, ok = <-routes:
+			}
+			if !ok {
+				return
+			}
+			notify()
+		}
+	}()
+	return stopped, nil
+}
+
+// drain reads a subscription channel until netlink closes it, the
+// subscription goroutine sends blocking and would otherwise leak.
+func drain[T any](ch <-chan T) {
+	go func() {
+		for range ch {
+		}
+	}()
+}
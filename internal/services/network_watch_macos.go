@@ -0,0 +1,75 @@
+//go:build darwin
+
+package services
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"golang.org/x/net/route"
+	"golang.org/x/sys/unix"
+)
+
+// watchPollInterval is the safety net re-read while the route socket
+// works. Service names, DNS and other configd-only settings changed with
+// networksetup never show up on it.
+const watchPollInterval = 5 * time.Second
+
+// startNetworkWatch opens a route socket and calls notify for interface,
+// address and default route messages. The returned channel is closed if
+// reading the socket fails before ctx is done.
+func startNetworkWatch(ctx context.Context, notify func()) (<-chan struct{}, error) {
+	fd, err := unix.Socket(unix.AF_ROUTE, unix.SOCK_RAW, unix.AF_UNSPEC)
+	if err != nil {
+		return nil, err
+	}
+	// Non-blocking so the runtime poller owns it and Close unblocks Read.
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	sock := os.NewFile(uintptr(fd), "route")
+	stop := context.AfterFunc(ctx, func() { sock.Close() })
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		defer func() {
+			if stop() {
+				sock.Close()
+			}
+		}()
+
+		buf := make([]byte, 2048)
+		for {
+			n, err := sock.Read(buf)
+			if err != nil {
+				return
+			}
+			msgs, err := route.ParseRIB(route.RIBTypeRoute, buf[:n])
+			if err != nil {
+				continue
+			}
+			for _, m := range msgs {
+				if relevantRouteMessage(m) {
+					notify()
+					break
+				}
+			}
+		}
+	}()
+	return stopped, nil
+}
+
+// relevantRouteMessage skips the ARP and cloned host routes that a busy
+// network produces all the time.
+func relevantRouteMessage(m route.Message) bool {
+	switch m := m.(type) {
+	case *route.InterfaceMessage, *route.InterfaceAddrMessage:
+		return true
+	case *route.RouteMessage:
+		return m.Flags&(unix.RTF_LLINFO|unix.RTF_WASCLONED|unix.RTF_HOST) == 0
+	}
+	return false
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"macbox/internal/filter"
+	"macbox/pkg/watcher"
+	"net/netip"
+	"sort"
+	"sync"
+)
+
+const (
+	defaultHistoryPackets = 100_000
+	defaultHistoryBytes   = 64 << 20
+
+	// Rough cost of everything except the payload: struct, timestamp,
+	// strings and the parsed map. Only used to enforce MaxBytes.
+	packetOverhead = 512
+
+	maxPageSize = 5000
+)
+
+// PacketStore keeps the most recent packets in arrival order. IDs and
+// timestamps only grow, so range lookups are binary searches.
+type PacketStore struct {
+	mu      sync.RWMutex
+	packets []watcher.UDPPacket
+	bytes   int
+	limits  watcher.HistoryLimits
+	nextID  int64
+	dropped int64
+	garbage int // evicted packets still held by the backing array
+}
+
+func NewPacketStore() *PacketStore {
+	return &PacketStore{
+		limits: watcher.HistoryLimits{
+			MaxPackets: defaultHistoryPackets,
+			MaxBytes:   defaultHistoryBytes,
+		},
+		nextID: 1,
+	}
+}
+
+// Append assigns the next ID to the packet, stores it and returns it.
+func (s *PacketStore) Append(p watcher.UDPPacket) watcher.UDPPacket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.ID = s.nextID
+	s.nextID++
+
+	s.packets = append(s.packets, p)
+	s.bytes += packetCost(p)
+	s.evict()
+
+	return p
+}
+
+func (s *PacketStore) SetLimits(limits watcher.HistoryLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+	s.evict()
+}
+
+func (s *PacketStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.packets = nil
+	s.bytes = 0
+	s.dropped = 0
+	s.garbage = 0
+}
+
+func (s *PacketStore) Stats() watcher.HistoryStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := watcher.HistoryStats{
+		Limits:  s.limits,
+		Count:   len(s.packets),
+		Bytes:   s.bytes,
+		Dropped: s.dropped,
+	}
+	if len(s.packets) > 0 {
+		stats.OldestID = s.packets[0].ID
+		stats.NewestID = s.packets[len(s.packets)-1].ID
+	}
+	return stats
+}
+
+func (s *PacketStore) Get(id int64) (watcher.UDPPacket, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := sort.Search(len(s.packets), func(i int) bool { return s.packets[i].ID >= id })
+	if i < len(s.packets) && s.packets[i].ID == id {
+		return s.packets[i], true
+	}
+	return watcher.UDPPacket{}, false
+}
+
+// Query returns one page of packets matching q, oldest first.
+func (s *PacketStore) Query(q watcher.PacketQuery) watcher.PacketPage {
+	page := watcher.PacketPage{Packets: []watcher.UDPPacket{}, Offset: q.Offset}
+
+	match, err := compileQuery(q)
+	if err != nil {
+		page.Error = err.Error()
+		return page
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	s.Each(q, match, func(p watcher.UDPPacket) bool {
+		if page.Total >= q.Offset && len(page.Packets) < limit {
+			page.Packets = append(page.Packets, p)
+		}
+		page.Total++
+		return true
+	})
+
+	return page
+}
+
+// Each calls fn for every stored packet within the ranges of q that also
+// passes match, oldest first, until fn returns false. Paging, source and
+// filter fields of q are ignored, compileQuery turns them into match.
+func (s *PacketStore) Each(q watcher.PacketQuery, match filter.Filter, fn func(watcher.UDPPacket) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lo, hi := s.bounds(q)
+	for i := lo; i < hi; i++ {
+		p := s.packets[i]
+		if match != nil && !match(p) {
+			continue
+		}
+		if !fn(p) {
+			return
+		}
+	}
+}
+
+// compileQuery combines the source and filter expression of q into one
+// filter.
+func compileQuery(q watcher.PacketQuery) (filter.Filter, error) {
+	match, err := filter.Compile(q.Filter)
+	if err != nil {
+		return nil, errors.New("Invalid filter: " + err.Error())
+	}
+	if q.Source == "" {
+		return match, nil
+	}
+
+	from, err := sourceFilter(q.Source)
+	if err != nil {
+		return nil, err
+	}
+	return func(p watcher.UDPPacket) bool {
+		return from(p.FromIP) && match(p)
+	}, nil
+}
+
+// sourceFilter matches the sender of a packet against an address and
+// port, an address, or a subnet: "10.0.0.5:14550", "10.0.0.5" or
+// "10.0.0.0/24".
+func sourceFilter(source string) (func(fromIP string) bool, error) {
+	addrOf := func(fromIP string) (netip.AddrPort, bool) {
+		ap, err := netip.ParseAddrPort(fromIP)
+		if err != nil {
+			return netip.AddrPort{}, false
+		}
+		return netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port()), true
+	}
+
+	if want, err := netip.ParseAddrPort(source); err == nil {
+		want = netip.AddrPortFrom(want.Addr().Unmap(), want.Port())
+		return func(fromIP string) bool {
+			ap, ok := addrOf(fromIP)
+			return ok && ap == want
+		}, nil
+	}
+	if want, err := netip.ParseAddr(source); err == nil {
+		want = want.Unmap()
+		return func(fromIP string) bool {
+			ap, ok := addrOf(fromIP)
+			if !ok {
+				return false
+			}
+			// "fe80::1" matches a sender on any zone.
+			addr := ap.Addr()
+			if want.Zone() == "" {
+				addr = addr.WithZone("")
+			}
+			return addr == want
+		}, nil
+	}
+	if want, err := netip.ParsePrefix(source); err == nil {
+		want = want.Masked()
+		return func(fromIP string) bool {
+			ap, ok := addrOf(fromIP)
+			return ok && want.Contains(ap.Addr().WithZone(""))
+		}, nil
+	}
+	return nil, fmt.Errorf("Invalid source: %q is not an address, address and port, or subnet", source)
+}
+
+// bounds narrows the slice to the ID and time ranges of q.
+// Caller must hold the lock.
+func (s *PacketStore) bounds(q watcher.PacketQuery) (int, int) {
+	lo, hi := 0, len(s.packets)
+
+	if q.FromID > 0 {
+		lo = max(lo, sort.Search(len(s.packets), func(i int) bool { return s.packets[i].ID >= q.FromID }))
+	}
+	if q.ToID > 0 {
+		hi = min(hi, sort.Search(len(s.packets), func(i int) bool { return s.packets[i].ID > q.ToID }))
+	}
+	if !q.Since.IsZero() {
+		lo = max(lo, sort.Search(len(s.packets), func(i int) bool { return !s.packets[i].Timestamp.Before(q.Since) }))
+	}
+	if !q.Until.IsZero() {
+		hi = min(hi, sort.Search(len(s.packets), func(i int) bool { return s.packets[i].Timestamp.After(q.Until) }))
+	}
+
+	if lo > hi {
+		lo = hi
+	}
+	return lo, hi
+}
+
+// evict drops the oldest packets until both limits hold.
+// Caller must hold the lock.
+func (s *PacketStore) evict() {
+	n := 0
+	for n < len(s.packets) {
+		overCount := s.limits.MaxPackets > 0 && len(s.packets)-n > s.limits.MaxPackets
+		overBytes := s.limits.MaxBytes > 0 && s.bytes > s.limits.MaxBytes
+		if !overCount && !overBytes {
+			break
+		}
+		s.bytes -= packetCost(s.packets[n])
+		n++
+	}
+	if n == 0 {
+		return
+	}
+
+	s.dropped += int64(n)
+	s.packets = s.packets[n:]
+
+	// Reslicing keeps the evicted packets reachable through the backing
+	// array, so copy once the dead head gets as large as the live tail.
+	s.garbage += n
+	if s.garbage > len(s.packets)+1024 {
+		s.packets = append([]watcher.UDPPacket(nil), s.packets...)
+		s.garbage = 0
+	}
+}
+
+func packetCost(p watcher.UDPPacket) int {
+	return len(p.Payload) + len(p.FromIP) + packetOverhead
+}
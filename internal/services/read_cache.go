@@ -0,0 +1,67 @@
+//go:build darwin || linux
+
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// resolverCacheTTL is how long DNS settings read by running a command are
+// reused. Changes made by this app are seen at once, changes made
+// elsewhere within the TTL.
+const resolverCacheTTL = 10 * time.Second
+
+// resolverCache holds the DNS servers and search domains read per service
+// or link, keyed by the command and its name.
+var resolverCache = newReadCache[[]string](resolverCacheTTL)
+
+// readCache keeps values that take a command to read and rarely change,
+// so the live loop does not run the command for every service on every
+// tick.
+type readCache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry[T]
+}
+
+type cacheEntry[T any] struct {
+	value T
+	read  time.Time
+}
+
+func newReadCache[T any](ttl time.Duration) *readCache[T] {
+	return &readCache[T]{ttl: ttl, entries: map[string]cacheEntry[T]{}}
+}
+
+// get returns the cached value of key, calling read when there is none
+// or it is older than the TTL.
+func (c *readCache[T]) get(key string, read func() T) T {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Since(e.read) < c.ttl {
+		return e.value
+	}
+
+	value := read()
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry[T]{value: value, read: time.Now()}
+	c.mu.Unlock()
+	return value
+}
+
+// forget drops key, the next get reads it again.
+func (c *readCache[T]) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// reset drops every entry.
+func (c *readCache[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"macbox/pkg/dhcp"
+	"macbox/pkg/mavlink"
+	"macbox/pkg/network"
+	"macbox/pkg/settings"
+	"macbox/pkg/watcher"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const settingsFileName = "settings.json"
+
+// migrations upgrade the raw JSON of a settings file by one version.
+// migrations[n] turns version n into version n+1.
+var migrations = map[int]func(raw map[string]any){
+	// Version 0 is a file without a "version" key. Its sections already
+	// match version 1, anything missing is filled with defaults on decode.
+	0: func(raw map[string]any) {},
+}
+
+type SettingsService struct {
+	mu       sync.Mutex
+	path     string
+	settings settings.Settings
+}
+
+func NewSettingsService() *SettingsService {
+	path := ""
+	if dir, err := os.UserConfigDir(); err == nil {
+		path = filepath.Join(dir, "macbox", settingsFileName)
+	}
+
+	return &SettingsService{
+		path:     path,
+		settings: defaultSettings(),
+	}
+}
+
+func defaultSettings() settings.Settings {
+	return settings.Settings{
+		Version: settings.CurrentVersion,
+		Watcher: watcher.WatcherConfig{
+			Protocol: "udp",
+			Port:     8080,
+			Parser:   "raw",
+		},
+		History: watcher.HistoryLimits{
+			MaxPackets: defaultHistoryPackets,
+			MaxBytes:   defaultHistoryBytes,
+		},
+		Ping: settings.PingSettings{
+			Count: 4,
+		},
+		Update: settings.UpdateSettings{
+			CheckOnStartup: true,
+		},
+		DHCPServer: dhcp.ServerConfig{
+			DNS:          []string{},
+			LeaseTime:    3600,
+			Reservations: []dhcp.Reservation{},
+		},
+		MAVLinkDiscovery: mavlink.DiscoveryConfig{
+			UDPPorts:     []int{},
+			TCPAddresses: []string{},
+		},
+		Profiles: []network.Profile{},
+	}
+}
+
+// Load reads the settings file, migrating it to the current version.
+// A missing file is not an error, defaults are kept.
+func (s *SettingsService) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		return errors.New("user config directory is not available")
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("corrupted settings file: %w", err)
+	}
+
+	version := 0
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > settings.CurrentVersion {
+		return fmt.Errorf("settings file version %d is newer than supported %d", version, settings.CurrentVersion)
+	}
+
+	migrated := version < settings.CurrentVersion
+	for ; version < settings.CurrentVersion; version++ {
+		if migrate, ok := migrations[version]; ok {
+			migrate(raw)
+		}
+	}
+	raw["version"] = settings.CurrentVersion
+
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	loaded := defaultSettings()
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("corrupted settings file: %w", err)
+	}
+	s.settings = loaded
+
+	if migrated {
+		return s.save()
+	}
+	return nil
+}
+
+func (s *SettingsService) Get() settings.Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings
+}
+
+func (s *SettingsService) Set(value settings.Settings) error {
+	return s.Update(func(current *settings.Settings) {
+		*current = value
+	})
+}
+
+// Update applies fn to the settings and writes the file if anything changed.
+func (s *SettingsService) Update(fn func(*settings.Settings)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, _ := json.Marshal(s.settings)
+	fn(&s.settings)
+	s.settings.Version = settings.CurrentVersion
+	after, _ := json.Marshal(s.settings)
+
+	if string(before) == string(after) {
+		return nil
+	}
+	return s.save()
+}
+
+// save writes the file atomically so a crash never leaves half a file.
+// Caller must hold the lock.
+func (s *SettingsService) save() error {
+	if s.path == "" {
+		return errors.New("user config directory is not available")
+	}
+
+	data, err := json.MarshalIndent(s.settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *SettingsService) Profiles() []network.Profile {
+	return s.Get().Profiles
+}
+
+func (s *SettingsService) Profile(name string) (network.Profile, bool) {
+	for _, p := range s.Profiles() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return network.Profile{}, false
+}
+
+// SaveProfile adds the profile or replaces the one with the same name.
+func (s *SettingsService) SaveProfile(profile network.Profile) error {
+	return s.Update(func(st *settings.Settings) {
+		// Get hands out the same backing array, never write into it.
+		st.Profiles = slices.Clone(st.Profiles)
+		for i, p := range st.Profiles {
+			if p.Name == profile.Name {
+				st.Profiles[i] = profile
+				return
+			}
+		}
+		st.Profiles = append(st.Profiles, profile)
+	})
+}
+
+func (s *SettingsService) DeleteProfile(name string) error {
+	return s.Update(func(st *settings.Settings) {
+		st.Profiles = slices.DeleteFunc(slices.Clone(st.Profiles), func(p network.Profile) bool {
+			return p.Name == name
+		})
+	})
+}
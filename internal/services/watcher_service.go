@@ -4,13 +4,19 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"macbox/internal/export"
 	"macbox/internal/parsers"
 	"macbox/pkg/watcher"
 	"net"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
 type WatcherService struct {
@@ -21,6 +27,7 @@ type WatcherService struct {
 	parsersList   []watcher.ProtocolParser
 	parsersMap    map[string]watcher.ProtocolParser
 	currentParser watcher.ProtocolParser
+	history       *PacketStore
 }
 
 func NewWatcherService() *WatcherService {
@@ -32,6 +39,7 @@ func NewWatcherService() *WatcherService {
 			},
 		},
 		parsersMap: make(map[string]watcher.ProtocolParser),
+		history:    NewPacketStore(),
 	}
 	service.registerParser(&parsers.RawParser{})
 	service.registerParser(&parsers.AsciiParser{})
@@ -88,17 +96,132 @@ func (w *WatcherService) SaveConfig(cfg watcher.WatcherConfig) {
 	w.state.Config = cfg
 }
 
-func (w *WatcherService) startUDP(ctx context.Context, port int, dataChan chan<- watcher.UDPPacket, errChan chan<- error) {
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf(":%d", port))
+func (w *WatcherService) QueryPackets(q watcher.PacketQuery) watcher.PacketPage {
+	return w.history.Query(q)
+}
+
+func (w *WatcherService) GetPacket(id int64) (watcher.UDPPacket, bool) {
+	return w.history.Get(id)
+}
+
+func (w *WatcherService) GetHistoryStats() watcher.HistoryStats {
+	return w.history.Stats()
+}
+
+func (w *WatcherService) SetHistoryLimits(limits watcher.HistoryLimits) {
+	w.history.SetLimits(limits)
+}
+
+func (w *WatcherService) ClearHistory() {
+	w.history.Clear()
+}
+
+func (w *WatcherService) ExportPackets(req watcher.ExportRequest) watcher.ExportResult {
+	result := watcher.ExportResult{Path: req.Path}
+
+	if err := export.CheckFormat(req.Format); err != nil {
+		result.Error = "Export failed: " + err.Error()
+		return result
+	}
+	match, err := compileQuery(req.Query)
 	if err != nil {
-		errChan <- err
-		return
+		result.Error = err.Error()
+		return result
+	}
+
+	// Copy the packets first, the history stays locked only while the
+	// matching ones are collected, not while the file is written.
+	var packets []watcher.UDPPacket
+	w.history.Each(req.Query, match, func(p watcher.UDPPacket) bool {
+		packets = append(packets, p)
+		return true
+	})
+
+	f, err := os.Create(req.Path)
+	if err != nil {
+		result.Error = "Cannot create file: " + err.Error()
+		return result
+	}
+
+	result.Count, err = export.Write(f, req.Format, packets)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		result.Error = "Export failed: " + err.Error()
+	}
+
+	return result
+}
+
+// listenNetwork picks "udp4"/"udp6" (or tcp) from the configured IP version,
+// falling back to the family of the multicast group or bind address.
+func listenNetwork(base string, cfg watcher.WatcherConfig) string {
+	switch cfg.IPVersion {
+	case "ipv4":
+		return base + "4"
+	case "ipv6":
+		return base + "6"
+	}
+
+	for _, addr := range []string{cfg.MulticastGroup, cfg.BindAddress} {
+		host, _, _ := strings.Cut(addr, "%")
+		if ip := net.ParseIP(host); ip != nil {
+			if ip.To4() != nil {
+				return base + "4"
+			}
+			return base + "6"
+		}
+	}
+	return base
+}
+
+func listenConfig(cfg watcher.WatcherConfig) net.ListenConfig {
+	var lc net.ListenConfig
+	if cfg.ReusePort {
+		lc.Control = reuseControl
+	}
+	return lc
+}
+
+// joinMulticast subscribes conn to the configured group on the chosen interface.
+func joinMulticast(conn *net.UDPConn, cfg watcher.WatcherConfig) error {
+	group := net.ParseIP(cfg.MulticastGroup)
+	if group == nil || !group.IsMulticast() {
+		return fmt.Errorf("invalid multicast group: %s", cfg.MulticastGroup)
+	}
+
+	var ifi *net.Interface
+	if cfg.MulticastIface != "" {
+		var err error
+		if ifi, err = net.InterfaceByName(cfg.MulticastIface); err != nil {
+			return fmt.Errorf("multicast interface %s: %w", cfg.MulticastIface, err)
+		}
+	}
+
+	if group.To4() != nil {
+		return ipv4.NewPacketConn(conn).JoinGroup(ifi, &net.UDPAddr{IP: group})
 	}
-	conn, err := net.ListenUDP("udp", addr)
+	return ipv6.NewPacketConn(conn).JoinGroup(ifi, &net.UDPAddr{IP: group})
+}
+
+func (w *WatcherService) startUDP(ctx context.Context, cfg watcher.WatcherConfig, dataChan chan<- watcher.UDPPacket, errChan chan<- error) {
+	lc := listenConfig(cfg)
+	addr := net.JoinHostPort(cfg.BindAddress, strconv.Itoa(cfg.Port))
+	pc, err := lc.ListenPacket(ctx, listenNetwork("udp", cfg), addr)
 	if err != nil {
 		errChan <- err
 		return
 	}
+	conn := pc.(*net.UDPConn)
+
+	if cfg.MulticastGroup != "" {
+		if err := joinMulticast(conn, cfg); err != nil {
+			conn.Close()
+			errChan <- err
+			return
+		}
+	}
 
 	go func() {
 		<-ctx.Done()
@@ -136,9 +259,10 @@ func (w *WatcherService) startUDP(ctx context.Context, port int, dataChan chan<-
 	}
 }
 
-func (w *WatcherService) startTCP(ctx context.Context, port int, dataChan chan<- watcher.UDPPacket, errChan chan<- error) {
-	addr := fmt.Sprintf(":%d", port)
-	listener, err := net.Listen("tcp", addr)
+func (w *WatcherService) startTCP(ctx context.Context, cfg watcher.WatcherConfig, dataChan chan<- watcher.UDPPacket, errChan chan<- error) {
+	lc := listenConfig(cfg)
+	addr := net.JoinHostPort(cfg.BindAddress, strconv.Itoa(cfg.Port))
+	listener, err := lc.Listen(ctx, listenNetwork("tcp", cfg), addr)
 	if err != nil {
 		errChan <- err
 		return
@@ -165,18 +289,88 @@ func (w *WatcherService) startTCP(ctx context.Context, port int, dataChan chan<-
 	}
 }
 
-func (w *WatcherService) handleTCPConnection(ctx context.Context, conn net.Conn, dataChan chan<- watcher.UDPPacket) {
+const (
+	tcpClientDialTimeout = 5 * time.Second
+	tcpClientMinBackoff  = 500 * time.Millisecond
+	tcpClientMaxBackoff  = 10 * time.Second
+)
+
+// startTCPClient connects to a device that is itself a TCP server
+// (e.g. SITL on tcp:5760) and keeps reconnecting with exponential backoff.
+func (w *WatcherService) startTCPClient(ctx context.Context, cfg watcher.WatcherConfig, dataChan chan<- watcher.UDPPacket, errChan chan<- error) {
+	if cfg.RemoteHost == "" {
+		errChan <- fmt.Errorf("remote host is required in TCP client mode")
+		return
+	}
+
+	remote := net.JoinHostPort(cfg.RemoteHost, strconv.Itoa(cfg.Port))
+	dialer := net.Dialer{Timeout: tcpClientDialTimeout}
+	backoff := tcpClientMinBackoff
+
+	defer w.setConnection(watcher.ConnectionState{State: "disconnected", Remote: remote})
+
+	for attempt := 1; ; attempt++ {
+		w.setConnection(watcher.ConnectionState{State: "connecting", Remote: remote, Attempt: attempt})
+
+		conn, err := dialer.DialContext(ctx, listenNetwork("tcp", cfg), remote)
+		if err == nil {
+			w.setConnection(watcher.ConnectionState{State: "connected", Remote: remote, Attempt: attempt})
+			attempt = 0
+			backoff = tcpClientMinBackoff
+
+			err = w.handleTCPConnection(ctx, conn, dataChan)
+			if err == nil {
+				err = fmt.Errorf("connection closed by remote")
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.setConnection(watcher.ConnectionState{
+			State:   "disconnected",
+			Remote:  remote,
+			Attempt: attempt,
+			RetryIn: backoff.Milliseconds(),
+			Error:   err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, tcpClientMaxBackoff)
+	}
+}
+
+func (w *WatcherService) setConnection(state watcher.ConnectionState) {
+	w.mu.Lock()
+	w.state.Connection = state
+	w.mu.Unlock()
+
+	runtime.EventsEmit(w.ctx, "watcher_connection", state)
+}
+
+// handleTCPConnection feeds the stream into dataChan until the peer closes
+// (nil) or reading fails.
+func (w *WatcherService) handleTCPConnection(ctx context.Context, conn net.Conn, dataChan chan<- watcher.UDPPacket) error {
 	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
 	remoteAddr := conn.RemoteAddr().String()
 	buffer := make([]byte, 4096)
 
 	for {
 		n, err := conn.Read(buffer)
 		if err != nil {
-			if err != io.EOF {
-				fmt.Printf("TCP read error from %s: %v\n", remoteAddr, err)
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
 			}
-			return
+			fmt.Printf("TCP read error from %s: %v\n", remoteAddr, err)
+			return err
 		}
 
 		toSend := make([]byte, n)
@@ -192,7 +386,7 @@ func (w *WatcherService) handleTCPConnection(ctx context.Context, conn net.Conn,
 		select {
 		case dataChan <- packet:
 		case <-ctx.Done():
-			return
+			return nil
 		}
 	}
 }
@@ -203,26 +397,29 @@ func (w *WatcherService) Start() {
 	w.mu.Lock()
 	w.cancelFunc = cancel
 	w.state.IsRunning = true
+	w.state.Connection = watcher.ConnectionState{}
 	if p, ok := w.parsersMap[w.state.Config.Parser]; ok {
 		w.currentParser = p
 	}
 	currentParser := w.currentParser
-	protocol := w.state.Config.Protocol
-	port := w.state.Config.Port
+	cfg := w.state.Config
+	protocol := cfg.Protocol
+	port := cfg.Port
 	w.mu.Unlock()
 
 	dataChan := make(chan watcher.UDPPacket, 100)
 	errChan := make(chan error)
 
-	if protocol == "tcp" {
-		go w.startTCP(ctx, port, dataChan, errChan)
-	} else {
-		go w.startUDP(ctx, port, dataChan, errChan)
+	switch protocol {
+	case "tcp":
+		go w.startTCP(ctx, cfg, dataChan, errChan)
+	case "tcp-client":
+		go w.startTCPClient(ctx, cfg, dataChan, errChan)
+	default:
+		go w.startUDP(ctx, cfg, dataChan, errChan)
 	}
 
 	go func() {
-		var id int64 = 0
-
 		defer func() {
 			w.mu.Lock()
 			w.state.IsRunning = false
@@ -235,7 +432,6 @@ func (w *WatcherService) Start() {
 				return
 
 			case packet := <-dataChan:
-				packet.ID = id
 				packet.Protocol = protocol
 				packet.Parser = currentParser.ID()
 				packet.Port = port
@@ -244,8 +440,8 @@ func (w *WatcherService) Start() {
 				parsedData, _ := currentParser.Parse(packet.Payload)
 				packet.ParsedData = parsedData
 
+				packet = w.history.Append(packet)
 				runtime.EventsEmit(w.ctx, "packet_received", packet)
-				id++
 
 			case err := <-errChan:
 				fmt.Printf("Listener Error: %v\n", err)
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package services
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reuseControl lets several processes bind the same port, so the watcher
+// can sit next to a GCS that already listens on it.
+func reuseControl(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
+		if sockErr == nil {
+			sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package services
+
+import "syscall"
+
+// reuseControl lets several processes bind the same port. Windows has no
+// SO_REUSEPORT, SO_REUSEADDR alone gives the same sharing semantics.
+func reuseControl(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(syscall.Handle(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"macbox/pkg/wifi"
+	"slices"
+	"sync"
+)
+
+// FakeWifiBackend is an in-memory Wi-Fi backend for tests and UI work on
+// machines without Wi-Fi. Secured networks accept any password except
+// WrongPassword.
+type FakeWifiBackend struct {
+	mu       sync.Mutex
+	Networks []wifi.Network
+	current  *wifi.Network
+}
+
+const WrongPassword = "wrong-password"
+
+func NewFakeWifiBackend() *FakeWifiBackend {
+	return &FakeWifiBackend{
+		Networks: []wifi.Network{
+			{SSID: "Drone-AP-01", BSSID: "02:00:00:00:01:01", Channel: 36, Frequency: 5180, RSSI: -48, Security: "WPA2 Personal"},
+			{SSID: "Drone-AP-02", BSSID: "02:00:00:00:01:02", Channel: 6, Frequency: 2437, RSSI: -67, Security: "WPA2 Personal"},
+			{SSID: "Field Router", BSSID: "02:00:00:00:02:01", Channel: 1, Frequency: 2412, RSSI: -72, Security: "WPA3"},
+			{SSID: "Open Lab", BSSID: "02:00:00:00:03:01", Channel: 11, Frequency: 2462, RSSI: -80, Security: "Open"},
+		},
+	}
+}
+
+func (f *FakeWifiBackend) Interfaces() ([]string, error) {
+	return []string{"wlan0"}, nil
+}
+
+func (f *FakeWifiBackend) Scan(ctx context.Context, iface string) ([]wifi.Network, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.Networks), ctx.Err()
+}
+
+func (f *FakeWifiBackend) Status(iface string) (wifi.Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := wifi.Status{Interface: iface, PoweredOn: true}
+	if n := f.current; n != nil {
+		status.Connected = true
+		status.SSID, status.BSSID, status.Channel = n.SSID, n.BSSID, n.Channel
+		status.RSSI, status.Noise, status.TxRate, status.Security = n.RSSI, -95, 144, n.Security
+	}
+	return status, nil
+}
+
+func (f *FakeWifiBackend) Join(ctx context.Context, iface string, req wifi.JoinRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := slices.IndexFunc(f.Networks, func(n wifi.Network) bool { return n.SSID == req.SSID })
+	switch {
+	case i < 0:
+		return fmt.Errorf("Network %q not found", req.SSID)
+	case f.Networks[i].Security != "Open" && (req.Password == "" || req.Password == WrongPassword):
+		return errors.New("Authentication failed: wrong password")
+	}
+
+	n := f.Networks[i]
+	f.current = &n
+	return nil
+}
+
+func (f *FakeWifiBackend) Leave(iface string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.current = nil
+	return nil
+}
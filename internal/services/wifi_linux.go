@@ -0,0 +1,390 @@
+//go:build linux
+
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"macbox/pkg/wifi"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// wpaCtrlDirs are where wpa_supplicant puts its control sockets, one per
+// interface, depending on the distribution.
+var wpaCtrlDirs = []string{"/run/wpa_supplicant", "/var/run/wpa_supplicant"}
+
+// wpaBackend drives wpa_supplicant through its control socket, the same
+// protocol wpa_cli speaks.
+type wpaBackend struct{}
+
+func NewWifiBackend() wifi.Backend {
+	return wpaBackend{}
+}
+
+func (wpaBackend) Interfaces() ([]string, error) {
+	entries, err := os.ReadDir("/sys/class/net")
+	if err != nil {
+		return nil, err
+	}
+
+	ifaces := []string{}
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join("/sys/class/net", e.Name(), "wireless")); err == nil {
+			ifaces = append(ifaces, e.Name())
+		}
+	}
+	return ifaces, nil
+}
+
+func (wpaBackend) Scan(ctx context.Context, iface string) ([]wifi.Network, error) {
+	conn, err := dialWPA(iface)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	if err := conn.ok("ATTACH"); err != nil {
+		return nil, err
+	}
+	if reply, err := conn.request("SCAN"); err != nil {
+		return nil, err
+	} else if reply != "OK" && reply != "FAIL-BUSY" {
+		// FAIL-BUSY: a scan is already running, its results will do.
+		return nil, fmt.Errorf("wpa_supplicant: scan failed: %s", reply)
+	}
+	event, err := conn.waitEvent(ctx, "CTRL-EVENT-SCAN-RESULTS", "CTRL-EVENT-SCAN-FAILED")
+	if err != nil {
+		return nil, fmt.Errorf("wpa_supplicant: scan %w", err)
+	}
+	if strings.Contains(event, "CTRL-EVENT-SCAN-FAILED") {
+		return nil, errors.New("wpa_supplicant: scan failed, is the interface up?")
+	}
+
+	reply, err := conn.request("SCAN_RESULTS")
+	if err != nil {
+		return nil, err
+	}
+	return parseScanResults(reply), nil
+}
+
+// parseScanResults reads the tab separated SCAN_RESULTS table:
+//
+//	bssid / frequency / signal level / flags / ssid
+//	02:00:00:00:01:01	5180	-48	[WPA2-PSK-CCMP][ESS]	Drone-AP-01
+func parseScanResults(reply string) []wifi.Network {
+	networks := []wifi.Network{}
+	for _, line := range strings.Split(reply, "\n")[1:] {
+		fields := strings.SplitN(line, "\t", 5)
+		if len(fields) < 5 {
+			continue
+		}
+		freq, _ := strconv.Atoi(fields[1])
+		rssi, _ := strconv.Atoi(fields[2])
+		networks = append(networks, wifi.Network{
+			SSID:      unescapeSSID(fields[4]),
+			BSSID:     fields[0],
+			Channel:   wifi.Channel(freq),
+			Frequency: freq,
+			RSSI:      rssi,
+			Security:  wpaSecurity(fields[3]),
+		})
+	}
+	return networks
+}
+
+func wpaSecurity(flags string) string {
+	switch {
+	case strings.Contains(flags, "SAE"):
+		return "WPA3"
+	case strings.Contains(flags, "EAP"):
+		return "WPA2 Enterprise"
+	case strings.Contains(flags, "WPA2-PSK"), strings.Contains(flags, "RSN-PSK"):
+		return "WPA2 Personal"
+	case strings.Contains(flags, "WPA-"):
+		return "WPA"
+	case strings.Contains(flags, "WEP"):
+		return "WEP"
+	}
+	return "Open"
+}
+
+// unescapeSSID undoes the printf escaping wpa_supplicant applies to
+// non-printable SSID bytes ("\xNN", "\\", "\"").
+func unescapeSSID(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if v, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func (wpaBackend) Status(iface string) (wifi.Status, error) {
+	conn, err := dialWPA(iface)
+	if err != nil {
+		return wifi.Status{}, err
+	}
+	defer conn.close()
+
+	reply, err := conn.request("STATUS")
+	if err != nil {
+		return wifi.Status{}, err
+	}
+	kv := parseKeyValues(reply)
+
+	status := wifi.Status{PoweredOn: kv["wpa_state"] != "INTERFACE_DISABLED"}
+	if kv["wpa_state"] != "COMPLETED" {
+		return status, nil
+	}
+
+	freq, _ := strconv.Atoi(kv["freq"])
+	status.Connected = true
+	status.SSID = unescapeSSID(kv["ssid"])
+	status.BSSID = kv["bssid"]
+	status.Channel = wifi.Channel(freq)
+	status.Security = wpaSecurity(kv["key_mgmt"])
+
+	if reply, err := conn.request("SIGNAL_POLL"); err == nil {
+		poll := parseKeyValues(reply)
+		status.RSSI, _ = strconv.Atoi(poll["RSSI"])
+		status.Noise, _ = strconv.Atoi(poll["NOISE"])
+		status.TxRate, _ = strconv.Atoi(poll["LINKSPEED"])
+	}
+	return status, nil
+}
+
+// Join adds a network block, selects it and waits for the association. A failed join removes the block
+// again. DHCP is left to whatever runs on the interface.
+func (wpaBackend) Join(ctx context.Context, iface string, req wifi.JoinRequest) error {
+	conn, err := dialWPA(iface)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	reply, err := conn.request("ADD_NETWORK")
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(reply)
+	if err != nil {
+		return fmt.Errorf("wpa_supplicant: add network: %s", reply)
+	}
+
+	err = conn.joinNetwork(ctx, id, req)
+	if err != nil {
+		_, _ = conn.request("REMOVE_NETWORK " + strconv.Itoa(id))
+		return err
+	}
+
+	// SELECT_NETWORK disabled every other network, let them back in for
+	// roaming and keep the new one for the next boot if the config is
+	// writable.
+	_, _ = conn.request("ENABLE_NETWORK all")
+	_, _ = conn.request("SAVE_CONFIG")
+	return nil
+}
+
+func (c *wpaConn) joinNetwork(ctx context.Context, id int, req wifi.JoinRequest) error {
+	set := func(key, value string) error {
+		return c.ok(fmt.Sprintf("SET_NETWORK %d %s %s", id, key, value))
+	}
+
+	// An unquoted value is read as hex, which sidesteps quoting the SSID.
+	if err := set("ssid", hex.EncodeToString([]byte(req.SSID))); err != nil {
+		return err
+	}
+	if req.Hidden {
+		if err := set("scan_ssid", "1"); err != nil {
+			return err
+		}
+	}
+
+	switch n := len(req.Password); {
+	case n == 0:
+		if err := set("key_mgmt", "NONE"); err != nil {
+			return err
+		}
+	case n == 5 || n == 13:
+		if err := set("key_mgmt", "NONE"); err != nil {
+			return err
+		}
+		if err := set("wep_key0", `"`+req.Password+`"`); err != nil {
+			return err
+		}
+	default:
+		// SAE needs a newer wpa_supplicant, fall back to plain WPA-PSK.
+		if set("key_mgmt", "WPA-PSK SAE") == nil {
+			_ = set("ieee80211w", "1")
+		} else if err := set("key_mgmt", "WPA-PSK"); err != nil {
+			return err
+		}
+		// wpa_supplicant takes everything between the outer quotes as is.
+		psk := req.Password
+		if len(psk) != 64 {
+			psk = `"` + psk + `"`
+		}
+		if err := set("psk", psk); err != nil {
+			return errors.New("wpa_supplicant rejected the password")
+		}
+	}
+
+	if err := c.ok("ATTACH"); err != nil {
+		return err
+	}
+	if err := c.ok("SELECT_NETWORK " + strconv.Itoa(id)); err != nil {
+		return err
+	}
+
+	event, err := c.waitEvent(ctx, "CTRL-EVENT-CONNECTED", "CTRL-EVENT-SSID-TEMP-DISABLED", "CTRL-EVENT-NETWORK-NOT-FOUND")
+	if err != nil {
+		return fmt.Errorf("Could not join %q: %w", req.SSID, err)
+	}
+	switch {
+	case strings.Contains(event, "CTRL-EVENT-SSID-TEMP-DISABLED"):
+		return fmt.Errorf("Could not join %q: authentication failed, check the password", req.SSID)
+	case strings.Contains(event, "CTRL-EVENT-NETWORK-NOT-FOUND"):
+		return fmt.Errorf("Could not join %q: network not found", req.SSID)
+	}
+	return nil
+}
+
+func (wpaBackend) Leave(iface string) error {
+	conn, err := dialWPA(iface)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+	return conn.ok("DISCONNECT")
+}
+
+func parseKeyValues(reply string) map[string]string {
+	kv := make(map[string]string)
+	for _, line := range strings.Split(reply, "\n") {
+		if k, v, ok := strings.Cut(line, "="); ok {
+			kv[k] = v
+		}
+	}
+	return kv
+}
+
+// wpaConn is one control socket connection. The client side is a
+// datagram socket bound to its own path so wpa_supplicant can reply.
+type wpaConn struct {
+	conn  *net.UnixConn
+	local string
+}
+
+var wpaConnSeq atomic.Uint64
+
+func dialWPA(iface string) (*wpaConn, error) {
+	remote := ""
+	for _, dir := range wpaCtrlDirs {
+		if path := filepath.Join(dir, iface); fileExists(path) {
+			remote = path
+			break
+		}
+	}
+	if remote == "" {
+		return nil, fmt.Errorf("wpa_supplicant is not running on %s", iface)
+	}
+
+	local := filepath.Join(os.TempDir(), fmt.Sprintf("macbox-wpa-%d-%d", os.Getpid(), wpaConnSeq.Add(1)))
+	conn, err := net.DialUnix("unixgram",
+		&net.UnixAddr{Name: local, Net: "unixgram"},
+		&net.UnixAddr{Name: remote, Net: "unixgram"})
+	if err != nil {
+		os.Remove(local)
+		return nil, errors.New(parseLinkError(err))
+	}
+	return &wpaConn{conn: conn, local: local}, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (c *wpaConn) close() {
+	// Leaves the event list if ATTACH was sent, ignored otherwise.
+	_, _ = c.conn.Write([]byte("DETACH"))
+	c.conn.Close()
+	os.Remove(c.local)
+}
+
+// request sends a command and returns its reply, skipping the unsolicited
+// "<N>EVENT" messages of an attached connection.
+func (c *wpaConn) request(cmd string) (string, error) {
+	if _, err := c.conn.Write([]byte(cmd)); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 16384)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.conn.SetReadDeadline(deadline)
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("wpa_supplicant: %s: %w", cmd, err)
+		}
+		if msg := string(buf[:n]); !strings.HasPrefix(msg, "<") {
+			return strings.TrimSpace(msg), nil
+		}
+	}
+}
+
+func (c *wpaConn) ok(cmd string) error {
+	reply, err := c.request(cmd)
+	if err != nil {
+		return err
+	}
+	if reply != "OK" {
+		return fmt.Errorf("wpa_supplicant: %s: %s", strings.Fields(cmd)[0], reply)
+	}
+	return nil
+}
+
+// waitEvent reads events until one contains any of names.
+func (c *wpaConn) waitEvent(ctx context.Context, names ...string) (string, error) {
+	buf := make([]byte, 4096)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", errors.New("timed out")
+		}
+		c.conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		n, err := c.conn.Read(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		msg := string(buf[:n])
+		for _, name := range names {
+			if strings.Contains(msg, name) {
+				return msg, nil
+			}
+		}
+	}
+}
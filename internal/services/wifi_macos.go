@@ -0,0 +1,231 @@
+//go:build darwin
+
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"macbox/pkg/wifi"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// airportBackend uses networksetup for power and joining and
+// system_profiler for scans, the airport tool is gone since macOS 14.4.
+// macOS hides BSSIDs from apps without location access, they stay empty.
+type airportBackend struct{}
+
+func NewWifiBackend() wifi.Backend {
+	return airportBackend{}
+}
+
+func (airportBackend) Interfaces() ([]string, error) {
+	out, err := exec.Command("networksetup", "-listallhardwareports").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// Hardware Port: Wi-Fi
+	// Device: en0
+	ifaces := []string{}
+	isWifi := false
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if port, ok := strings.CutPrefix(line, "Hardware Port: "); ok {
+			isWifi = port == "Wi-Fi" || port == "AirPort"
+		} else if device, ok := strings.CutPrefix(line, "Device: "); ok && isWifi {
+			ifaces = append(ifaces, device)
+		}
+	}
+	return ifaces, nil
+}
+
+// airportInfo is the part of `system_profiler SPAirPortDataType -json`
+// this backend reads.
+type airportInfo struct {
+	SPAirPortDataType []struct {
+		Interfaces []struct {
+			Name    string           `json:"_name"`
+			Current *airportNetwork  `json:"spairport_current_network_information"`
+			Others  []airportNetwork `json:"spairport_airport_other_local_wireless_networks"`
+		} `json:"spairport_airport_interfaces"`
+	}
+}
+
+type airportNetwork struct {
+	Name        string `json:"_name"`
+	Channel     any    `json:"spairport_network_channel"` // 36 or "36 (5GHz, 80MHz)"
+	Security    string `json:"spairport_security_mode"`
+	SignalNoise string `json:"spairport_signal_noise"` // "-52 dBm / -94 dBm"
+	Rate        int    `json:"spairport_network_rate"`
+}
+
+func (n airportNetwork) toNetwork() wifi.Network {
+	rssi, _ := n.signalNoise()
+	channel := n.channel()
+	return wifi.Network{
+		SSID:      n.Name,
+		Channel:   channel,
+		Frequency: channelFrequency(channel, fmt.Sprint(n.Channel)),
+		RSSI:      rssi,
+		Security:  airportSecurity(n.Security),
+	}
+}
+
+func (n airportNetwork) channel() int {
+	switch v := n.Channel.(type) {
+	case float64:
+		return int(v)
+	case string:
+		c, _ := strconv.Atoi(strings.Fields(v + " ")[0])
+		return c
+	}
+	return 0
+}
+
+func (n airportNetwork) signalNoise() (int, int) {
+	signal, noise, _ := strings.Cut(n.SignalNoise, "/")
+	s, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(signal), " dBm"))
+	no, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(noise), " dBm"))
+	return s, no
+}
+
+// channelFrequency maps a channel back to MHz using the band in the
+// system_profiler text ("2GHz", "5GHz", "6GHz").
+func channelFrequency(channel int, text string) int {
+	switch {
+	case channel == 0:
+		return 0
+	case strings.Contains(text, "6GHz"):
+		return 5950 + 5*channel
+	case strings.Contains(text, "5GHz"), channel > 14:
+		return 5000 + 5*channel
+	case channel == 14:
+		return 2484
+	}
+	return 2407 + 5*channel
+}
+
+func airportSecurity(mode string) string {
+	mode = strings.TrimPrefix(mode, "spairport_security_mode_")
+	switch {
+	case mode == "" || mode == "none":
+		return "Open"
+	case strings.Contains(mode, "wpa3"):
+		return "WPA3"
+	case strings.Contains(mode, "enterprise"):
+		return "WPA2 Enterprise"
+	case strings.Contains(mode, "wpa2"):
+		return "WPA2 Personal"
+	case strings.Contains(mode, "wpa"):
+		return "WPA"
+	case strings.Contains(mode, "wep"):
+		return "WEP"
+	}
+	return mode
+}
+
+func readAirportInfo(ctx context.Context, iface string) (current *airportNetwork, others []airportNetwork, err error) {
+	out, err := exec.CommandContext(ctx, "system_profiler", "SPAirPortDataType", "-json").Output()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var info airportInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, nil, fmt.Errorf("system_profiler: %w", err)
+	}
+	for _, data := range info.SPAirPortDataType {
+		for _, i := range data.Interfaces {
+			if i.Name == iface {
+				return i.Current, i.Others, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("Wi-Fi interface %s not found", iface)
+}
+
+func (airportBackend) Scan(ctx context.Context, iface string) ([]wifi.Network, error) {
+	current, others, err := readAirportInfo(ctx, iface)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := []wifi.Network{}
+	if current != nil {
+		networks = append(networks, current.toNetwork())
+	}
+	for _, n := range others {
+		networks = append(networks, n.toNetwork())
+	}
+	return networks, nil
+}
+
+func (airportBackend) Status(iface string) (wifi.Status, error) {
+	status := wifi.Status{PoweredOn: airportPower(iface)}
+	if !status.PoweredOn {
+		return status, nil
+	}
+
+	current, _, err := readAirportInfo(context.Background(), iface)
+	if err != nil || current == nil {
+		return status, err
+	}
+
+	n := current.toNetwork()
+	status.Connected = true
+	status.SSID, status.Channel, status.Security = n.SSID, n.Channel, n.Security
+	status.RSSI, status.Noise = current.signalNoise()
+	status.TxRate = current.Rate
+	return status, nil
+}
+
+func airportPower(iface string) bool {
+	// Wi-Fi Power (en0): On
+	out, err := exec.Command("networksetup", "-getairportpower", iface).Output()
+	return err == nil && strings.HasSuffix(strings.TrimSpace(string(out)), "On")
+}
+
+func setAirportPower(iface string, on bool) error {
+	state := "off"
+	if on {
+		state = "on"
+	}
+	return networksetup("-setairportpower", iface, state)
+}
+
+// Join powers Wi-Fi on if needed and joins. networksetup exits 0 when the
+// join fails and only says so on stdout.
+func (airportBackend) Join(ctx context.Context, iface string, req wifi.JoinRequest) error {
+	if !airportPower(iface) {
+		if err := setAirportPower(iface, true); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"-setairportnetwork", iface, req.SSID}
+	if req.Password != "" {
+		args = append(args, req.Password)
+	}
+	out, err := exec.CommandContext(ctx, "networksetup", args...).CombinedOutput()
+	if err != nil {
+		return errors.New(parseNetworkError(out, err))
+	}
+
+	// "Could not find network Foo." / "Failed to join network Foo."
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		return fmt.Errorf("Could not join %q: %s", req.SSID, msg)
+	}
+	return nil
+}
+
+// Leave turns Wi-Fi off: macOS has no command to just disassociate, and
+// with power on it would rejoin a preferred network right away.
+func (airportBackend) Leave(iface string) error {
+	return setAirportPower(iface, false)
+}
@@ -0,0 +1,131 @@
+//go:build darwin || linux
+
+package services
+
+import (
+	"context"
+	"errors"
+	"macbox/pkg/wifi"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	wifiScanTimeout = 15 * time.Second
+	wifiJoinTimeout = 30 * time.Second
+)
+
+var errNoWifi = errors.New("No Wi-Fi interface found")
+
+// WifiService scans and joins Wi-Fi networks through a platform backend.
+// Scans and joins are serialized, the hardware does one at a time anyway.
+type WifiService struct {
+	ctx     context.Context
+	backend wifi.Backend
+	mu      sync.Mutex
+}
+
+func NewWifiService(backend wifi.Backend) *WifiService {
+	return &WifiService{backend: backend}
+}
+
+func (s *WifiService) SetContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
+func (s *WifiService) GetInterfaces() []string {
+	ifaces, err := s.backend.Interfaces()
+	if err != nil {
+		return []string{}
+	}
+	return ifaces
+}
+
+// Scan lists the visible networks, strongest first. An empty iface picks
+// the first Wi-Fi device.
+func (s *WifiService) Scan(iface string) wifi.ScanResult {
+	iface, err := s.resolve(iface)
+	if err != nil {
+		return wifi.ScanResult{Networks: []wifi.Network{}, Error: err.Error()}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(s.context(), wifiScanTimeout)
+	defer cancel()
+
+	networks, err := s.backend.Scan(ctx, iface)
+	if err != nil {
+		return wifi.ScanResult{Networks: []wifi.Network{}, Error: err.Error()}
+	}
+	sort.SliceStable(networks, func(i, j int) bool { return networks[i].RSSI > networks[j].RSSI })
+	return wifi.ScanResult{Networks: networks}
+}
+
+// GetStatus reports the current association and signal.
+func (s *WifiService) GetStatus(iface string) wifi.Status {
+	iface, err := s.resolve(iface)
+	if err != nil {
+		return wifi.Status{Error: err.Error()}
+	}
+
+	status, err := s.backend.Status(iface)
+	status.Interface = iface
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+func (s *WifiService) Join(iface string, req wifi.JoinRequest) string {
+	if errs := req.Validate(); len(errs) > 0 {
+		return errs.Error()
+	}
+	iface, err := s.resolve(iface)
+	if err != nil {
+		return err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(s.context(), wifiJoinTimeout)
+	defer cancel()
+
+	return errString(s.backend.Join(ctx, iface, req))
+}
+
+func (s *WifiService) Leave(iface string) string {
+	iface, err := s.resolve(iface)
+	if err != nil {
+		return err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return errString(s.backend.Leave(iface))
+}
+
+func (s *WifiService) resolve(iface string) (string, error) {
+	if iface != "" {
+		return iface, nil
+	}
+	ifaces, err := s.backend.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	if len(ifaces) == 0 {
+		return "", errNoWifi
+	}
+	return ifaces[0], nil
+}
+
+func (s *WifiService) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package tools
+
+import (
+	"bufio"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// arpComplete is ATF_COM, the entry has a resolved address.
+const arpComplete = 0x2
+
+// readARPTable reads the kernel neighbour table from /proc/net/arp:
+//
+//	IP address       HW type     Flags       HW address            Mask     Device
+//	192.168.1.1      0x1         0x2         a4:2b:b0:11:22:33     *        eth0
+func readARPTable() (map[netip.Addr]string, error) {
+	f, err := os.Open("/proc/net/arp")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	table := map[netip.Addr]string{}
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[2], 0, 32)
+		if err != nil || flags&arpComplete == 0 {
+			continue
+		}
+		ip, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			continue
+		}
+		if mac, err := net.ParseMAC(fields[3]); err == nil {
+			table[ip] = mac.String()
+		}
+	}
+	return table, scanner.Err()
+}
@@ -0,0 +1,57 @@
+//go:build darwin
+
+package tools
+
+import (
+	"bufio"
+	"net"
+	"net/netip"
+	"os/exec"
+	"strings"
+)
+
+// readARPTable parses `arp -an`:
+//
+//	? (192.168.1.1) at a4:2b:b0:1:22:33 on en0 ifscope [ethernet]
+//	? (192.168.1.7) at (incomplete) on en0 ifscope [ethernet]
+func readARPTable() (map[netip.Addr]string, error) {
+	out, err := exec.Command("arp", "-an").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	table := map[netip.Addr]string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[2] != "at" {
+			continue
+		}
+		ip, err := netip.ParseAddr(strings.Trim(fields[1], "()"))
+		if err != nil {
+			continue
+		}
+		if mac, ok := parseShortMAC(fields[3]); ok {
+			table[ip] = mac
+		}
+	}
+	return table, scanner.Err()
+}
+
+// parseShortMAC reads the BSD form that drops leading zeros (0:1b:2:...).
+func parseShortMAC(s string) (string, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 6 {
+		return "", false
+	}
+	for i, p := range parts {
+		if len(p) == 1 {
+			parts[i] = "0" + p
+		}
+	}
+	mac, err := net.ParseMAC(strings.Join(parts, ":"))
+	if err != nil {
+		return "", false
+	}
+	return mac.String(), true
+}
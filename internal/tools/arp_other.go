@@ -0,0 +1,12 @@
+//go:build !darwin && !linux
+
+package tools
+
+import (
+	"errors"
+	"net/netip"
+)
+
+func readARPTable() (map[netip.Addr]string, error) {
+	return nil, errors.New("Reading the ARP table is not supported on this system")
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"bufio"
+	_ "embed"
+	"net"
+	"strings"
+	"sync"
+)
+
+//go:embed oui.txt
+var ouiData string
+
+var (
+	ouiOnce  sync.Once
+	ouiTable map[string]string
+)
+
+// Vendor looks up the manufacturer of a MAC address by its first three
+// bytes. Randomized (locally administered) addresses have none.
+func Vendor(mac string) string {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) < 3 {
+		return ""
+	}
+	if hw[0]&0x02 != 0 {
+		return "Randomized"
+	}
+
+	ouiOnce.Do(func() {
+		ouiTable = map[string]string{}
+		scanner := bufio.NewScanner(strings.NewReader(ouiData))
+		for scanner.Scan() {
+			prefix, vendor, ok := strings.Cut(scanner.Text(), "\t")
+			if ok && !strings.HasPrefix(prefix, "#") {
+				ouiTable[prefix] = vendor
+			}
+		}
+	})
+	return ouiTable[strings.ToUpper(strings.ReplaceAll(hw[:3].String(), ":", ""))]
+}
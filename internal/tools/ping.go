@@ -65,3 +65,31 @@ func (pt *PingTool) isAvailable() bool {
 	defer pt.mu.Unlock()
 	return pt.pinger != nil
 }
+
+// Reachable sends a single echo request and reports whether it was
+// answered within timeout.
+func Reachable(ctx context.Context, ip string, timeout time.Duration) bool {
+	_, ok := PingOnce(ctx, ip, timeout)
+	return ok
+}
+
+// PingOnce sends a single echo request and returns its round trip time,
+// false if no answer came within timeout.
+func PingOnce(ctx context.Context, ip string, timeout time.Duration) (time.Duration, bool) {
+	pinger, err := probing.NewPinger(ip)
+	if err != nil {
+		return 0, false
+	}
+
+	pinger.Count = 1
+	pinger.Timeout = timeout
+	if runtime.GOOS == "windows" {
+		pinger.SetPrivileged(true)
+	}
+
+	if err := pinger.RunWithContext(ctx); err != nil {
+		return 0, false
+	}
+	stats := pinger.Statistics()
+	return stats.AvgRtt, stats.PacketsRecv > 0
+}
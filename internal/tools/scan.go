@@ -0,0 +1,289 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"macbox/pkg/scan"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	scanWorkers        = 64
+	scanPingTimeout    = time.Second
+	scanConnectTimeout = 500 * time.Millisecond
+	scanLookupTimeout  = time.Second
+	// scanARPMaxAge is how long a read of the ARP table is reused, the
+	// workers look up every live host in it.
+	scanARPMaxAge = 500 * time.Millisecond
+	// scanProgressInterval throttles progress callbacks on large subnets.
+	scanProgressInterval = 200 * time.Millisecond
+)
+
+// ScanTool sweeps a subnet for live hosts. A host counts as live if it
+// answers a ping, accepts or refuses a TCP connection on one of the
+// requested ports, or shows up in the ARP table, which the probes fill
+// even for hosts that drop ICMP.
+type ScanTool struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func NewScanTool() *ScanTool {
+	return &ScanTool{}
+}
+
+// Start probes every address of prefix and returns when the sweep is done
+// or Stop was called. onHost is called for each live host as it is found,
+// and again for a host whose MAC address only turned up at the end.
+func (st *ScanTool) Start(ctx context.Context, prefix netip.Prefix, req scan.Request, onHost func(scan.Host), onProgress func(scan.Progress)) error {
+	st.mu.Lock()
+	if st.cancel != nil {
+		st.mu.Unlock()
+		return errors.New("A scan is already running.")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	st.cancel = cancel
+	st.mu.Unlock()
+
+	defer func() {
+		st.mu.Lock()
+		st.cancel = nil
+		st.mu.Unlock()
+		cancel()
+	}()
+
+	s := &sweep{
+		req:      req,
+		prefix:   prefix.Masked(),
+		local:    localMACs(),
+		found:    map[netip.Addr]scan.Host{},
+		onHost:   onHost,
+		progress: scan.Progress{Total: scan.HostCount(prefix)},
+		report:   onProgress,
+	}
+	s.run(ctx)
+	return nil
+}
+
+func (st *ScanTool) Stop() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.cancel != nil {
+		st.cancel()
+	}
+}
+
+// sweep is the state of one Start.
+type sweep struct {
+	req    scan.Request
+	prefix netip.Prefix
+	local  map[netip.Addr]string // addresses of this machine -> MAC
+
+	mu           sync.Mutex
+	found        map[netip.Addr]scan.Host
+	onHost       func(scan.Host)
+	progress     scan.Progress
+	report       func(scan.Progress)
+	lastProgress time.Time
+
+	arpMu   sync.Mutex
+	arp     map[netip.Addr]string
+	arpRead time.Time
+}
+
+func (s *sweep) run(ctx context.Context) {
+	addrs := make(chan netip.Addr)
+	var wg sync.WaitGroup
+	for range scanWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for addr := range addrs {
+				s.probe(ctx, addr)
+			}
+		}()
+	}
+
+feed:
+	for _, addr := range scan.Hosts(s.prefix) {
+		select {
+		case addrs <- addr:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(addrs)
+	wg.Wait()
+
+	if ctx.Err() == nil {
+		s.collectARP(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress.Done = true
+	s.report(s.progress)
+}
+
+// probe pings addr and tries the requested ports at the same time.
+func (s *sweep) probe(ctx context.Context, addr netip.Addr) {
+	host := scan.Host{IP: addr.String(), FoundBy: []string{}, OpenPorts: []int{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	answered := false
+	for _, port := range s.req.Ports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			open, alive := connect(ctx, addr, port)
+			mu.Lock()
+			defer mu.Unlock()
+			answered = answered || alive
+			if open {
+				host.OpenPorts = append(host.OpenPorts, port)
+			}
+		}()
+	}
+	rtt, pinged := PingOnce(ctx, host.IP, scanPingTimeout)
+	wg.Wait()
+
+	if pinged {
+		host.RTT = float64(rtt.Microseconds()) / 1000
+		host.FoundBy = append(host.FoundBy, "icmp")
+	}
+	if answered {
+		host.FoundBy = append(host.FoundBy, "tcp")
+	}
+	slices.Sort(host.OpenPorts)
+
+	if pinged || answered {
+		host.MAC = s.mac(addr)
+		s.finish(ctx, addr, host)
+	}
+	s.step()
+}
+
+// connect reports whether port is open, and whether the host answered at
+// all: a refused connection means it is there.
+func connect(ctx context.Context, addr netip.Addr, port int) (open, alive bool) {
+	dialer := net.Dialer{Timeout: scanConnectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(addr.String(), strconv.Itoa(port)))
+	if err == nil {
+		conn.Close()
+		return true, true
+	}
+	return false, errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// collectARP adds the hosts that only answered ARP and fills in the MAC
+// of hosts found before their ARP entry was complete.
+func (s *sweep) collectARP(ctx context.Context) {
+	table, err := readARPTable()
+	if err != nil {
+		return
+	}
+	for addr, mac := range table {
+		if !s.prefix.Contains(addr) || ctx.Err() != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		host, ok := s.found[addr]
+		s.mu.Unlock()
+
+		switch {
+		case !ok:
+			host = scan.Host{IP: addr.String(), FoundBy: []string{"arp"}, OpenPorts: []int{}, MAC: mac}
+		case host.MAC == "":
+			host.MAC = mac
+			host.FoundBy = append(host.FoundBy, "arp")
+		default:
+			continue
+		}
+		s.finish(ctx, addr, host)
+	}
+}
+
+// finish completes host with the vendor and name and hands it on.
+func (s *sweep) finish(ctx context.Context, addr netip.Addr, host scan.Host) {
+	host.Vendor = Vendor(host.MAC)
+	if s.req.ReverseDNS && host.Hostname == "" {
+		host.Hostname = lookupName(ctx, addr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.found[addr]; !ok {
+		s.progress.Found++
+	}
+	s.found[addr] = host
+	s.onHost(host)
+}
+
+func (s *sweep) step() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress.Scanned++
+	if time.Since(s.lastProgress) < scanProgressInterval {
+		return
+	}
+	s.lastProgress = time.Now()
+	s.report(s.progress)
+}
+
+// mac looks addr up in a recent read of the ARP table, this machine's
+// own addresses are not in it.
+func (s *sweep) mac(addr netip.Addr) string {
+	if mac, ok := s.local[addr]; ok {
+		return mac
+	}
+
+	s.arpMu.Lock()
+	defer s.arpMu.Unlock()
+	if time.Since(s.arpRead) > scanARPMaxAge {
+		if table, err := readARPTable(); err == nil {
+			s.arp = table
+		}
+		s.arpRead = time.Now()
+	}
+	return s.arp[addr]
+}
+
+func lookupName(ctx context.Context, addr netip.Addr) string {
+	ctx, cancel := context.WithTimeout(ctx, scanLookupTimeout)
+	defer cancel()
+	names, err := net.DefaultResolver.LookupAddr(ctx, addr.String())
+	if err != nil || len(names) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
+
+func localMACs() map[netip.Addr]string {
+	macs := map[netip.Addr]string{}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return macs
+	}
+	for _, ifi := range ifaces {
+		addrs, _ := ifi.Addrs()
+		for _, a := range addrs {
+			ipnet, ok := a.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			if addr, ok := netip.AddrFromSlice(ipnet.IP.To4()); ok {
+				macs[addr] = ifi.HardwareAddr.String()
+			}
+		}
+	}
+	return macs
+}
@@ -0,0 +1,46 @@
+package dhcp
+
+import "time"
+
+// ClientLease is the lease the system DHCP client holds for a service.
+type ClientLease struct {
+	Service    string    `json:"service"`
+	Device     string    `json:"device"`
+	Source     string    `json:"source"` // ipconfig/dhclient/kernel, where the details come from
+	IP         string    `json:"ip"`
+	SubnetMask string    `json:"subnetMask"`
+	Router     string    `json:"router"`
+	DNS        []string  `json:"dns"`
+	DomainName string    `json:"domainName"`
+	Server     string    `json:"server"`    // server identifier, option 54
+	LeaseTime  int       `json:"leaseTime"` // seconds, 0 if unknown
+	Expires    time.Time `json:"expires"`   // zero if unknown
+	Options    []Option  `json:"options"`   // everything the server sent, as received
+	Error      string    `json:"error"`
+}
+
+type Option struct {
+	Code  int    `json:"code"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Offer is one answer to a test DISCOVER. More than one server on a
+// segment usually means a rogue one.
+type Offer struct {
+	Server     string   `json:"server"` // server identifier, option 54
+	From       string   `json:"from"`   // source address of the packet
+	OfferedIP  string   `json:"offeredIp"`
+	SubnetMask string   `json:"subnetMask"`
+	Router     string   `json:"router"`
+	DNS        []string `json:"dns"`
+	LeaseTime  int      `json:"leaseTime"`
+	Current    bool     `json:"current"` // the server of the lease the device holds
+	Options    []Option `json:"options"`
+}
+
+type DiscoverResult struct {
+	Device string  `json:"device"`
+	Offers []Offer `json:"offers"`
+	Error  string  `json:"error"`
+}
@@ -0,0 +1,49 @@
+package dhcp
+
+import "time"
+
+// ServerConfig describes the built-in DHCPv4 server. ServerIP must already
+// be assigned to Device, the server answers from it and hands it out as
+// the DHCP server identifier.
+type ServerConfig struct {
+	Device       string        `json:"device"` // e.g. en5, eth1
+	ServerIP     string        `json:"serverIp"`
+	SubnetMask   string        `json:"subnetMask"` // dotted or prefix length
+	PoolStart    string        `json:"poolStart"`
+	PoolEnd      string        `json:"poolEnd"`
+	Router       string        `json:"router"`    // optional, option 3
+	DNS          []string      `json:"dns"`       // optional, option 6
+	LeaseTime    int           `json:"leaseTime"` // seconds
+	Reservations []Reservation `json:"reservations"`
+}
+
+// Reservation always hands IP to the client with MAC. IP may lie outside
+// the pool but must be inside the subnet.
+type Reservation struct {
+	MAC      string `json:"mac"`
+	IP       string `json:"ip"`
+	Hostname string `json:"hostname"` // label only, not sent to the client
+}
+
+type Lease struct {
+	MAC      string    `json:"mac"`
+	IP       string    `json:"ip"`
+	Hostname string    `json:"hostname"` // from the client, option 12
+	State    string    `json:"state"`    // offered/bound
+	Static   bool      `json:"static"`   // from a reservation
+	Expires  time.Time `json:"expires"`
+}
+
+type ServerState struct {
+	Config  ServerConfig `json:"config"`
+	Running bool         `json:"running"`
+	Error   string       `json:"error"` // why the server stopped, if it did on its own
+	Leases  []Lease      `json:"leases"`
+}
+
+// LeaseEvent is emitted on every change of the lease table.
+type LeaseEvent struct {
+	Type  string    `json:"type"` // offer/bind/renew/release/decline/expire
+	Lease Lease     `json:"lease"`
+	Time  time.Time `json:"time"`
+}
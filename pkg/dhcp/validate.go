@@ -0,0 +1,155 @@
+package dhcp
+
+import (
+	"fmt"
+	"macbox/pkg/network"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+const (
+	MinLeaseTime = 60
+	MaxLeaseTime = 7 * 24 * 3600
+)
+
+// Validate checks the configuration and normalizes it in place: the mask
+// becomes dotted, addresses and MACs get their canonical form.
+func (c *ServerConfig) Validate() network.FieldErrors {
+	var errs network.FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, network.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	c.Device = strings.TrimSpace(c.Device)
+	if c.Device == "" {
+		add("device", "interface is required")
+	}
+	if c.LeaseTime < MinLeaseTime || c.LeaseTime > MaxLeaseTime {
+		add("leaseTime", "must be between %d and %d seconds", MinLeaseTime, MaxLeaseTime)
+	}
+
+	serverIP, ok := parseIPv4(c.ServerIP)
+	if !ok {
+		add("serverIp", "%q is not a valid IPv4 address", c.ServerIP)
+	}
+	bits, mask, ok := network.ParseMask(c.SubnetMask)
+	if !ok {
+		add("subnetMask", "%q is not a valid subnet mask or prefix length", c.SubnetMask)
+	} else if bits > 30 {
+		add("subnetMask", "/%d leaves no room for clients", bits)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	c.ServerIP, c.SubnetMask = serverIP.String(), mask
+
+	subnet := netip.PrefixFrom(serverIP, bits).Masked()
+	// usable reports why addr cannot be handed out, or "" if it can.
+	usable := func(addr netip.Addr) string {
+		switch {
+		case !subnet.Contains(addr):
+			return fmt.Sprintf("%s is outside of %s", addr, subnet)
+		case addr == subnet.Addr():
+			return fmt.Sprintf("%s is the network address of %s", addr, subnet)
+		case addr == Broadcast(subnet):
+			return fmt.Sprintf("%s is the broadcast address of %s", addr, subnet)
+		}
+		return ""
+	}
+	if msg := usable(serverIP); msg != "" {
+		add("serverIp", "%s", msg)
+	}
+
+	start, okStart := parseIPv4(c.PoolStart)
+	end, okEnd := parseIPv4(c.PoolEnd)
+	switch {
+	case !okStart:
+		add("poolStart", "%q is not a valid IPv4 address", c.PoolStart)
+	case usable(start) != "":
+		add("poolStart", "%s", usable(start))
+	default:
+		c.PoolStart = start.String()
+	}
+	switch {
+	case !okEnd:
+		add("poolEnd", "%q is not a valid IPv4 address", c.PoolEnd)
+	case usable(end) != "":
+		add("poolEnd", "%s", usable(end))
+	case okStart && end.Less(start):
+		add("poolEnd", "pool ends before it starts")
+	default:
+		c.PoolEnd = end.String()
+	}
+
+	if router := strings.TrimSpace(c.Router); router != "" {
+		addr, ok := parseIPv4(router)
+		switch {
+		case !ok:
+			add("router", "%q is not a valid IPv4 address", c.Router)
+		case usable(addr) != "":
+			add("router", "%s", usable(addr))
+		default:
+			c.Router = addr.String()
+		}
+	}
+
+	for i, server := range c.DNS {
+		addr, ok := parseIPv4(server)
+		if !ok {
+			add("dns", "%q is not a valid IPv4 address", server)
+			continue
+		}
+		c.DNS[i] = addr.String()
+	}
+
+	macs := map[string]bool{}
+	ips := map[netip.Addr]bool{}
+	for i := range c.Reservations {
+		r := &c.Reservations[i]
+		field := fmt.Sprintf("reservations.%d.", i)
+
+		hw, err := net.ParseMAC(strings.TrimSpace(r.MAC))
+		switch {
+		case err != nil || len(hw) != 6:
+			add(field+"mac", "%q is not a valid MAC address", r.MAC)
+		case macs[hw.String()]:
+			add(field+"mac", "%s is reserved twice", hw)
+		default:
+			r.MAC = hw.String()
+			macs[r.MAC] = true
+		}
+
+		addr, ok := parseIPv4(r.IP)
+		switch {
+		case !ok:
+			add(field+"ip", "%q is not a valid IPv4 address", r.IP)
+		case usable(addr) != "":
+			add(field+"ip", "%s", usable(addr))
+		case addr == serverIP:
+			add(field+"ip", "%s is the server address", addr)
+		case ips[addr]:
+			add(field+"ip", "%s is reserved twice", addr)
+		default:
+			r.IP = addr.String()
+			ips[addr] = true
+		}
+		r.Hostname = strings.TrimSpace(r.Hostname)
+	}
+
+	return errs
+}
+
+func parseIPv4(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	return addr, err == nil && addr.Is4()
+}
+
+// Broadcast returns the last address of prefix.
+func Broadcast(prefix netip.Prefix) netip.Addr {
+	a := prefix.Masked().Addr().As4()
+	for i := prefix.Bits(); i < 32; i++ {
+		a[i/8] |= 1 << (7 - i%8)
+	}
+	return netip.AddrFrom4(a)
+}
@@ -0,0 +1,50 @@
+package mavlink
+
+import "time"
+
+// StandardUDPPorts are listened on by every discovery: 14550 is where
+// vehicles send to a GCS, 14540 the PX4 offboard/SITL port.
+var StandardUDPPorts = []int{14550, 14540}
+
+// StandardTCPAddresses are connected to by every discovery, 5760 is the
+// TCP server of ArduPilot SITL and of many telemetry bridges.
+var StandardTCPAddresses = []string{"127.0.0.1:5760"}
+
+// DiscoveryConfig adds to the standard ports.
+type DiscoveryConfig struct {
+	UDPPorts     []int    `json:"udpPorts"`     // listened on
+	TCPAddresses []string `json:"tcpAddresses"` // host:port, connected to
+	Broadcast    bool     `json:"broadcast"`    // send HEARTBEATs to the broadcast address of every interface
+}
+
+// Endpoint is one socket discovery uses.
+type Endpoint struct {
+	Transport string `json:"transport"` // udp/tcp
+	Address   string `json:"address"`   // :14550 or 127.0.0.1:5760
+	Connected bool   `json:"connected"` // tcp only
+	Error     string `json:"error"`
+}
+
+// Vehicle is one system/component that sent a HEARTBEAT. The same one
+// talking on two ports is listed twice.
+type Vehicle struct {
+	SystemID       int       `json:"systemId"`
+	ComponentID    int       `json:"componentId"`
+	Transport      string    `json:"transport"` // udp/tcp
+	Source         string    `json:"source"`    // ip:port the frames come from
+	Endpoint       string    `json:"endpoint"`  // Endpoint.Address they arrive on
+	Autopilot      string    `json:"autopilot"` // MAV_AUTOPILOT without prefix, e.g. ARDUPILOTMEGA
+	Type           string    `json:"type"`      // MAV_TYPE without prefix, e.g. QUADROTOR
+	State          string    `json:"state"`     // MAV_STATE without prefix, e.g. STANDBY
+	Armed          bool      `json:"armed"`
+	Firmware       string    `json:"firmware"` // e.g. 4.5.1 or 1.15.0-beta, empty until AUTOPILOT_VERSION arrives
+	MAVLinkVersion int       `json:"mavlinkVersion"`
+	LastSeen       time.Time `json:"lastSeen"`
+}
+
+type DiscoveryState struct {
+	Config    DiscoveryConfig `json:"config"`
+	Running   bool            `json:"running"`
+	Endpoints []Endpoint      `json:"endpoints"`
+	Vehicles  []Vehicle       `json:"vehicles"`
+}
@@ -0,0 +1,52 @@
+package mavlink
+
+import (
+	"fmt"
+	"macbox/pkg/network"
+	"net"
+	"slices"
+	"strconv"
+)
+
+func (c *DiscoveryConfig) Validate() network.FieldErrors {
+	var errs network.FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, network.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, p := range c.UDPPorts {
+		if p < 1 || p > 65535 {
+			add("udpPorts", "%d is not a valid port", p)
+		}
+	}
+	for _, a := range c.TCPAddresses {
+		host, port, err := net.SplitHostPort(a)
+		n, _ := strconv.Atoi(port)
+		if err != nil || host == "" || n < 1 || n > 65535 {
+			add("tcpAddresses", "%q is not host:port", a)
+		}
+	}
+	return errs
+}
+
+// UDPPortList is the standard ports followed by the configured ones,
+// without duplicates.
+func (c DiscoveryConfig) UDPPortList() []int {
+	ports := slices.Clone(StandardUDPPorts)
+	for _, p := range c.UDPPorts {
+		if !slices.Contains(ports, p) {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+func (c DiscoveryConfig) TCPAddressList() []string {
+	addrs := slices.Clone(StandardTCPAddresses)
+	for _, a := range c.TCPAddresses {
+		if !slices.Contains(addrs, a) {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs
+}
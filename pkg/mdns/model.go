@@ -0,0 +1,42 @@
+package mdns
+
+import "time"
+
+// BrowseRequest selects what to look for. Without types every type that
+// answers the DNS-SD enumeration (_services._dns-sd._udp) is browsed.
+type BrowseRequest struct {
+	Types     []string `json:"types"`     // e.g. _http._tcp
+	Domain    string   `json:"domain"`    // empty = local
+	Interface string   `json:"interface"` // device, empty = every multicast capable one
+}
+
+// Service is one resolved instance.
+type Service struct {
+	Instance string    `json:"instance"` // e.g. Camera 1
+	Type     string    `json:"type"`     // e.g. _rtsp._tcp
+	Domain   string    `json:"domain"`
+	HostName string    `json:"hostName"` // e.g. camera-1.local
+	Port     int       `json:"port"`
+	TXT      []string  `json:"txt"` // key=value as advertised
+	IPv4     []string  `json:"ipv4"`
+	IPv6     []string  `json:"ipv6"`
+	Expires  time.Time `json:"expires"` // from the record TTL
+}
+
+// Registration is a test service this machine advertises.
+type Registration struct {
+	Instance  string   `json:"instance"`
+	Type      string   `json:"type"`
+	Port      int      `json:"port"`
+	TXT       []string `json:"txt"`
+	Interface string   `json:"interface"` // empty = every multicast capable one
+}
+
+type BrowserState struct {
+	Browsing      bool           `json:"browsing"`
+	Request       BrowseRequest  `json:"request"`
+	Types         []string       `json:"types"` // service types seen, enumerated or requested
+	Services      []Service      `json:"services"`
+	Registrations []Registration `json:"registrations"`
+	Error         string         `json:"error"`
+}
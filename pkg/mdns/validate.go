@@ -0,0 +1,59 @@
+package mdns
+
+import (
+	"fmt"
+	"macbox/pkg/network"
+	"regexp"
+	"strings"
+)
+
+// serviceType is _name._tcp or _name._udp (RFC 6763 section 7), the name
+// is at most 15 letters, digits and hyphens.
+var serviceType = regexp.MustCompile(`^_[A-Za-z0-9]([A-Za-z0-9-]{0,13}[A-Za-z0-9])?\._(tcp|udp)$`)
+
+// NormalizeType accepts a type with or without the trailing domain, e.g.
+// "_http._tcp.local.", and returns the bare "_http._tcp".
+func NormalizeType(t string) string {
+	t = strings.TrimSuffix(strings.TrimSpace(t), ".")
+	t = strings.TrimSuffix(t, ".local")
+	return t
+}
+
+func (r *BrowseRequest) Validate() network.FieldErrors {
+	var errs network.FieldErrors
+	for i, t := range r.Types {
+		r.Types[i] = NormalizeType(t)
+		if !serviceType.MatchString(r.Types[i]) {
+			errs = append(errs, network.FieldError{Field: "types", Message: fmt.Sprintf("%q is not a service type like _http._tcp", t)})
+		}
+	}
+	return errs
+}
+
+func (r *Registration) Validate() network.FieldErrors {
+	var errs network.FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, network.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	r.Instance = strings.TrimSpace(r.Instance)
+	r.Type = NormalizeType(r.Type)
+	switch {
+	case r.Instance == "":
+		add("instance", "required")
+	case len(r.Instance) > 63:
+		add("instance", "at most 63 bytes")
+	}
+	if !serviceType.MatchString(r.Type) {
+		add("type", "%q is not a service type like _http._tcp", r.Type)
+	}
+	if r.Port < 1 || r.Port > 65535 {
+		add("port", "%d is not a valid port", r.Port)
+	}
+	for _, txt := range r.TXT {
+		if len(txt) > 255 {
+			add("txt", "%.20q... is longer than 255 bytes", txt)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"reflect"
+	"slices"
+)
+
+// InterfaceDiff is what changed between two reads of the interface tree,
+// keyed by device.
+type InterfaceDiff struct {
+	Added   []HardwareInterface `json:"added"`
+	Removed []string            `json:"removed"` // devices
+	Changed []HardwareInterface `json:"changed"`
+	Order   []string            `json:"order"` // devices in display order, nil if unchanged
+}
+
+func (d InterfaceDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && d.Order == nil
+}
+
+// DiffInterfaces compares two interface trees.
+func DiffInterfaces(old, new []HardwareInterface) InterfaceDiff {
+	diff := InterfaceDiff{
+		Added:   []HardwareInterface{},
+		Removed: []string{},
+		Changed: []HardwareInterface{},
+		Order:   make([]string, 0, len(new)),
+	}
+
+	before := make(map[string]HardwareInterface, len(old))
+	for _, hw := range old {
+		before[hw.Device] = hw
+	}
+
+	for _, hw := range new {
+		diff.Order = append(diff.Order, hw.Device)
+		prev, ok := before[hw.Device]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, hw)
+		case !reflect.DeepEqual(prev, hw):
+			diff.Changed = append(diff.Changed, hw)
+		}
+		delete(before, hw.Device)
+	}
+
+	oldOrder := make([]string, 0, len(old))
+	for _, hw := range old {
+		oldOrder = append(oldOrder, hw.Device)
+		if _, gone := before[hw.Device]; gone {
+			diff.Removed = append(diff.Removed, hw.Device)
+		}
+	}
+
+	if slices.Equal(oldOrder, diff.Order) {
+		diff.Order = nil
+	}
+	return diff
+}
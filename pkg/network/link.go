@@ -0,0 +1,66 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	MinMTU = 68 // smallest MTU IPv4 allows
+	MaxMTU = 65535
+)
+
+func ValidateMTU(mtu int) FieldErrors {
+	if mtu < MinMTU || mtu > MaxMTU {
+		return FieldErrors{{Field: "mtu", Message: fmt.Sprintf("%d is outside of %d-%d", mtu, MinMTU, MaxMTU)}}
+	}
+	return nil
+}
+
+// ParseMedia splits a media string as printed by ifconfig,
+// "1000baseT <full-duplex,flow-control>", into subtype and options.
+func ParseMedia(media string) (string, []string) {
+	subtype, rest, _ := strings.Cut(strings.TrimSpace(media), " ")
+	rest = strings.Trim(strings.TrimSpace(rest), "<>")
+
+	return subtype, strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// MediaString is the inverse of ParseMedia.
+func MediaString(subtype string, options []string) string {
+	if len(options) == 0 {
+		return subtype
+	}
+	return subtype + " <" + strings.Join(options, ",") + ">"
+}
+
+// MediaSpeed reads the speed in Mbit/s from a media subtype, e.g.
+// "100baseTX" = 100, "10Gbase-T" = 10000. Returns 0 if there is none.
+func MediaSpeed(subtype string) int {
+	digits := 0
+	for digits < len(subtype) && subtype[digits] >= '0' && subtype[digits] <= '9' {
+		digits++
+	}
+	speed, err := strconv.Atoi(subtype[:digits])
+	if err != nil {
+		return 0
+	}
+	if strings.HasPrefix(subtype[digits:], "G") {
+		speed *= 1000
+	}
+	return speed
+}
+
+// MediaDuplex returns "full" or "half" from the media options.
+func MediaDuplex(options []string) string {
+	for _, opt := range options {
+		switch opt {
+		case "full-duplex":
+			return "full"
+		case "half-duplex":
+			return "half"
+		}
+	}
+	return ""
+}
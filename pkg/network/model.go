@@ -1,28 +1,107 @@
 package network
 
+import "time"
+
 type HardwareInterface struct {
 	Name            string           `json:"name"`
 	Device          string           `json:"device"`
 	Mac             string           `json:"mac"`
 	IsActive        bool             `json:"isActive"` // For indicator (green/gray)
+	Enabled         bool             `json:"enabled"`  // administratively up
+	Parent          string           `json:"parent"`   // device of the parent port, VLANs only
+	VLANID          int              `json:"vlanId"`
+	Link            LinkInfo         `json:"link"`
+	Stats           InterfaceStats   `json:"stats"`
 	LogicInterfaces []LogicInterface `json:"logicInterfaces"`
 }
 
+// LinkInfo is the physical side of a device. Speed and Duplex are what the
+// link negotiated, Media is what is selected ("autoselect" or a fixed
+// media such as "100baseTX <full-duplex>").
+type LinkInfo struct {
+	MTU     int    `json:"mtu"`
+	Speed   int    `json:"speed"`  // Mbit/s, 0 = unknown or no link
+	Duplex  string `json:"duplex"` // "full", "half" or "" when unknown
+	Media   string `json:"media"`
+	Carrier bool   `json:"carrier"`
+}
+
+// InterfaceStats are the device counters since boot (or since the device
+// was created) and the throughput over the last few seconds.
+type InterfaceStats struct {
+	RxBytes   uint64  `json:"rxBytes"`
+	TxBytes   uint64  `json:"txBytes"`
+	RxPackets uint64  `json:"rxPackets"`
+	TxPackets uint64  `json:"txPackets"`
+	RxErrors  uint64  `json:"rxErrors"`
+	TxErrors  uint64  `json:"txErrors"`
+	RxDropped uint64  `json:"rxDropped"`
+	TxDropped uint64  `json:"txDropped"`
+	RxBps     float64 `json:"rxBps"` // bits per second
+	TxBps     float64 `json:"txBps"`
+}
+
 type LogicInterface struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Device  string `json:"device"`
-	IP      string `json:"ip"`
-	Mask    string `json:"mask"`
-	Gateway string `json:"gateway"`
-	Method  string `json:"method"` // "DHCP" or "Manual"
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Device        string      `json:"device"`
+	IP            string      `json:"ip"`
+	Mask          string      `json:"mask"`
+	Gateway       string      `json:"gateway"`
+	Order         int         `json:"order"` // 1-based service priority, 0 = not ordered
+	Enabled       bool        `json:"enabled"`
+	Method        string      `json:"method"`        // "DHCP" or "Manual"
+	DNS           []string    `json:"dns"`           // configured servers, empty = from DHCP
+	SearchDomains []string    `json:"searchDomains"` // configured domains, empty = from DHCP
+	Aliases       []IPAddress `json:"aliases"`       // extra addresses on the same device
+	IPv6          IPv6Config  `json:"ipv6"`
+	Routes        []Route     `json:"routes"` // static routes via this service
+}
+
+type IPAddress struct {
+	IP   string `json:"ip"`
+	Mask string `json:"mask"`
+}
+
+type IPv6Config struct {
+	Method    string        `json:"method"` // "Automatic", "Manual", "Link-local only" or "Off"
+	Addresses []IPv6Address `json:"addresses"`
+	Router    string        `json:"router"`
+}
+
+type IPv6Address struct {
+	IP           string `json:"ip"`
+	PrefixLength int    `json:"prefixLength"`
+}
+
+// IPv6Update is the IPv6 part of an UpdatePayload. IP, PrefixLength and
+// Router are only used with the Manual method.
+type IPv6Update struct {
+	Method       string `json:"method"`
+	IP           string `json:"ip"`
+	PrefixLength int    `json:"prefixLength"`
+	Router       string `json:"router"`
 }
 
 type UpdatePayload struct {
-	OldName string `json:"oldName"`
-	NewName string `json:"newName"`
-	Method  string `json:"method"`
-	IP      string `json:"ip"`
-	Mask    string `json:"mask"`
-	Gateway string `json:"gateway"`
+	OldName       string      `json:"oldName"`
+	NewName       string      `json:"newName"`
+	Method        string      `json:"method"`
+	IP            string      `json:"ip"`
+	Mask          string      `json:"mask"`
+	Gateway       string      `json:"gateway"`
+	DNS           []string    `json:"dns"`           // nil = leave as is, empty = clear
+	SearchDomains []string    `json:"searchDomains"` // nil = leave as is, empty = clear
+	IPv6          *IPv6Update `json:"ipv6"`          // nil = leave as is
+}
+
+type ConfirmOptions struct {
+	Timeout    int    `json:"timeout"`    // seconds to wait for confirmation before reverting
+	PingTarget string `json:"pingTarget"` // confirm automatically once this host answers
+}
+
+type PendingChange struct {
+	Description string    `json:"description"`
+	Deadline    time.Time `json:"deadline"`
+	PingTarget  string    `json:"pingTarget"`
 }
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Profile is a named set of services per hardware port that can be
+// applied in one go, e.g. "Drone lab", "Field router", "DHCP".
+type Profile struct {
+	Name      string           `json:"name"`
+	Services  []ProfileService `json:"services"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+type ProfileService struct {
+	HardwarePort string   `json:"hardwarePort"` // e.g. "Wi-Fi", "USB 10/100/1000 LAN"
+	Name         string   `json:"name"`
+	Method       string   `json:"method"` // "DHCP" or "Manual", anything else is left untouched
+	IP           string   `json:"ip"`
+	Mask         string   `json:"mask"`
+	Gateway      string   `json:"gateway"`
+	DNS          []string `json:"dns"` // nil = leave as is, empty = clear
+}
+
+type ProfileChange struct {
+	Action       string   `json:"action"` // create/update/remove
+	HardwarePort string   `json:"hardwarePort"`
+	Service      string   `json:"service"`
+	Fields       []string `json:"fields"` // "ip: 10.0.0.2 -> 10.0.0.3"
+}
+
+// Ports returns the hardware ports a profile covers, in profile order.
+func (p Profile) Ports() []string {
+	var ports []string
+	for _, s := range p.Services {
+		if !slices.Contains(ports, s.HardwarePort) {
+			ports = append(ports, s.HardwarePort)
+		}
+	}
+	return ports
+}
+
+// Validate checks every manual entry like an UpdatePayload and normalizes
+// masks to dotted form. Field names are prefixed with the service name.
+func (p *Profile) Validate() FieldErrors {
+	var errs FieldErrors
+	if strings.TrimSpace(p.Name) == "" {
+		errs = append(errs, FieldError{Field: "name", Message: "profile name is required"})
+	}
+
+	for i := range p.Services {
+		s := &p.Services[i]
+		if s.Method != "Manual" {
+			continue
+		}
+
+		payload := UpdatePayload{OldName: s.Name, Method: s.Method, IP: s.IP, Mask: s.Mask, Gateway: s.Gateway}
+		for _, fe := range payload.Validate(nil) {
+			errs = append(errs, FieldError{Field: s.Name + "." + fe.Field, Message: fe.Message})
+		}
+		s.IP, s.Mask, s.Gateway = payload.IP, payload.Mask, payload.Gateway
+	}
+	return errs
+}
+
+// DiffProfile lists what applying p would change on top of live. Only
+// ports mentioned by the profile are touched: there, services missing from
+// the profile are removed and missing services are created.
+func DiffProfile(p Profile, live []ProfileService) []ProfileChange {
+	ports := p.Ports()
+	changes := []ProfileChange{}
+
+	for _, want := range p.Services {
+		i := slices.IndexFunc(live, func(s ProfileService) bool {
+			return s.HardwarePort == want.HardwarePort && s.Name == want.Name
+		})
+		if i < 0 {
+			changes = append(changes, ProfileChange{
+				Action:       "create",
+				HardwarePort: want.HardwarePort,
+				Service:      want.Name,
+				Fields:       describeService(want),
+			})
+			continue
+		}
+
+		if fields := diffService(live[i], want); len(fields) > 0 {
+			changes = append(changes, ProfileChange{
+				Action:       "update",
+				HardwarePort: want.HardwarePort,
+				Service:      want.Name,
+				Fields:       fields,
+			})
+		}
+	}
+
+	for _, have := range live {
+		if !slices.Contains(ports, have.HardwarePort) {
+			continue
+		}
+		if !slices.ContainsFunc(p.Services, func(s ProfileService) bool {
+			return s.HardwarePort == have.HardwarePort && s.Name == have.Name
+		}) {
+			changes = append(changes, ProfileChange{
+				Action:       "remove",
+				HardwarePort: have.HardwarePort,
+				Service:      have.Name,
+			})
+		}
+	}
+
+	return changes
+}
+
+func diffService(have, want ProfileService) []string {
+	var fields []string
+	field := func(name, from, to string) {
+		if from != to {
+			fields = append(fields, fmt.Sprintf("%s: %s -> %s", name, orNone(from), orNone(to)))
+		}
+	}
+
+	// Other methods mean the profile does not manage addressing.
+	if want.Method == "DHCP" || want.Method == "Manual" {
+		field("method", have.Method, want.Method)
+		if want.Method == "Manual" {
+			field("ip", have.IP, want.IP)
+			field("mask", have.Mask, want.Mask)
+			field("gateway", have.Gateway, want.Gateway)
+		}
+	}
+
+	if want.DNS != nil {
+		field("dns", strings.Join(have.DNS, ", "), strings.Join(want.DNS, ", "))
+	}
+
+	return fields
+}
+
+func describeService(s ProfileService) []string {
+	fields := []string{"method: " + orNone(s.Method)}
+	if s.Method == "Manual" {
+		fields = append(fields, "ip: "+orNone(s.IP), "mask: "+orNone(s.Mask), "gateway: "+orNone(s.Gateway))
+	}
+	if s.DNS != nil {
+		fields = append(fields, "dns: "+orNone(strings.Join(s.DNS, ", ")))
+	}
+	return fields
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Route is a static IPv4 route. Persistent routes are stored with the
+// service and come back after a reboot, temporary ones live until the
+// link goes down.
+type Route struct {
+	Destination string `json:"destination"` // network address, e.g. "10.10.0.0"
+	Mask        string `json:"mask"`
+	Gateway     string `json:"gateway"`
+	Device      string `json:"device"`
+	Persistent  bool   `json:"persistent"`
+}
+
+// Validate checks the route and normalizes it in place: the mask becomes
+// dotted and host bits are cleared from the destination.
+func (r *Route) Validate() FieldErrors {
+	var errs FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	dst, err := netip.ParseAddr(strings.TrimSpace(r.Destination))
+	if err != nil || !dst.Is4() {
+		add("destination", "%q is not a valid IPv4 address", r.Destination)
+	}
+	bits, mask, ok := ParseMask(r.Mask)
+	if !ok {
+		add("mask", "%q is not a valid subnet mask or prefix length", r.Mask)
+	}
+	gw, err := netip.ParseAddr(strings.TrimSpace(r.Gateway))
+	if err != nil || !gw.Is4() {
+		add("gateway", "%q is not a valid IPv4 address", r.Gateway)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	prefix := netip.PrefixFrom(dst, bits).Masked()
+	if prefix.Contains(gw) {
+		add("gateway", "%s is inside of the routed network %s", gw, prefix)
+	}
+
+	r.Destination, r.Mask, r.Gateway = prefix.Addr().String(), mask, gw.String()
+	return errs
+}
+
+// Same reports whether r and other describe the same route, regardless of
+// device and persistence.
+func (r Route) Same(other Route) bool {
+	return r.Destination == other.Destination && r.Mask == other.Mask && r.Gateway == other.Gateway
+}
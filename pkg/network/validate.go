@@ -0,0 +1,271 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string `json:"field"` // json name of the UpdatePayload field
+	Message string `json:"message"`
+}
+
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "Invalid configuration: " + strings.Join(parts, "; ")
+}
+
+// Validate checks the payload before anything is sent to the system and
+// normalizes it in place: a CIDR mask ("/24" or "24") becomes dotted.
+// existing is the current interface tree, used to reject a subnet that
+// overlaps one already assigned to another service.
+func (p *UpdatePayload) Validate(existing []HardwareInterface) FieldErrors {
+	var errs FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	p.OldName = strings.TrimSpace(p.OldName)
+	p.NewName = strings.TrimSpace(p.NewName)
+
+	if p.OldName == "" {
+		add("oldName", "service name is required")
+	}
+	if p.NewName != "" && p.NewName != p.OldName {
+		for _, hw := range existing {
+			for _, li := range hw.LogicInterfaces {
+				if li.Name == p.NewName {
+					add("newName", "service %q already exists", p.NewName)
+				}
+			}
+		}
+	}
+
+	for i, server := range p.DNS {
+		addr, err := netip.ParseAddr(strings.TrimSpace(server))
+		if err != nil {
+			add("dns", "%q is not a valid IP address", server)
+			continue
+		}
+		p.DNS[i] = addr.String()
+	}
+
+	for i, domain := range p.SearchDomains {
+		domain = strings.TrimSuffix(strings.TrimSpace(domain), ".")
+		if !isDomainName(domain) {
+			add("searchDomains", "%q is not a valid domain name", p.SearchDomains[i])
+			continue
+		}
+		p.SearchDomains[i] = domain
+	}
+
+	if p.IPv6 != nil {
+		errs = append(errs, p.IPv6.validate()...)
+	}
+
+	switch p.Method {
+	case "DHCP":
+		return errs
+	case "Manual":
+	default:
+		add("method", "must be DHCP or Manual")
+		return errs
+	}
+
+	ip, err := netip.ParseAddr(strings.TrimSpace(p.IP))
+	if err != nil || !ip.Is4() {
+		add("ip", "%q is not a valid IPv4 address", p.IP)
+	}
+
+	bits, mask, ok := ParseMask(p.Mask)
+	if !ok {
+		add("mask", "%q is not a valid subnet mask or prefix length", p.Mask)
+	} else {
+		p.Mask = mask
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	p.IP = ip.String()
+
+	prefix := netip.PrefixFrom(ip, bits).Masked()
+
+	switch {
+	case ip.IsUnspecified(), ip.IsLoopback(), ip.IsMulticast(), ip == netip.AddrFrom4([4]byte{255, 255, 255, 255}):
+		add("ip", "%s cannot be assigned to an interface", ip)
+	case bits < 31 && ip == prefix.Addr():
+		add("ip", "%s is the network address of %s", ip, prefix)
+	case bits < 31 && ip == broadcast(prefix):
+		add("ip", "%s is the broadcast address of %s", ip, prefix)
+	}
+
+	if gw := strings.TrimSpace(p.Gateway); gw != "" {
+		gwAddr, err := netip.ParseAddr(gw)
+		switch {
+		case err != nil || !gwAddr.Is4():
+			add("gateway", "%q is not a valid IPv4 address", p.Gateway)
+		case !prefix.Contains(gwAddr):
+			add("gateway", "%s is outside of %s", gwAddr, prefix)
+		case gwAddr == ip:
+			add("gateway", "gateway cannot be the interface address")
+		case bits < 31 && (gwAddr == prefix.Addr() || gwAddr == broadcast(prefix)):
+			add("gateway", "%s is the network or broadcast address of %s", gwAddr, prefix)
+		default:
+			p.Gateway = gwAddr.String()
+		}
+	}
+
+	for _, hw := range existing {
+		for _, li := range hw.LogicInterfaces {
+			if li.Name == p.OldName || li.IP == "" {
+				continue
+			}
+			other, ok := interfacePrefix(li.IP, li.Mask)
+			if ok && other.Overlaps(prefix) {
+				add("ip", "%s overlaps %s already used by %q", prefix, other, li.Name)
+			}
+		}
+	}
+
+	return errs
+}
+
+// IPv6Methods are the configuration methods accepted in IPv6Update.
+var IPv6Methods = []string{"Automatic", "Manual", "Link-local only", "Off"}
+
+// validate checks and normalizes the IPv6 part of a payload. Field names
+// are prefixed with "ipv6." so the UI can match them to its inputs.
+func (u *IPv6Update) validate() FieldErrors {
+	var errs FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: "ipv6." + field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !slices.Contains(IPv6Methods, u.Method) {
+		add("method", "must be one of %s", strings.Join(IPv6Methods, ", "))
+		return errs
+	}
+	if u.Method != "Manual" {
+		return errs
+	}
+
+	ip, err := netip.ParseAddr(strings.TrimSpace(u.IP))
+	switch {
+	case err != nil || !ip.Is6() || ip.Is4In6():
+		add("ip", "%q is not a valid IPv6 address", u.IP)
+	case ip.Zone() != "":
+		add("ip", "%s must not carry a zone", ip)
+	case ip.IsUnspecified(), ip.IsLoopback(), ip.IsMulticast():
+		add("ip", "%s cannot be assigned to an interface", ip)
+	}
+	if u.PrefixLength < 1 || u.PrefixLength > 128 {
+		add("prefixLength", "%d is not a valid prefix length", u.PrefixLength)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	u.IP = ip.String()
+
+	if router := strings.TrimSpace(u.Router); router != "" {
+		// Routers usually advertise their link-local address, which is
+		// never inside the configured prefix.
+		addr, err := netip.ParseAddr(router)
+		prefix := netip.PrefixFrom(ip, u.PrefixLength).Masked()
+		switch {
+		case err != nil || !addr.Is6() || addr.Is4In6():
+			add("router", "%q is not a valid IPv6 address", u.Router)
+		case addr == ip:
+			add("router", "router cannot be the interface address")
+		case !addr.IsLinkLocalUnicast() && !prefix.Contains(addr):
+			add("router", "%s is outside of %s and not link-local", addr, prefix)
+		default:
+			u.Router = addr.WithZone("").String()
+		}
+	}
+
+	return errs
+}
+
+// ParseMask accepts a dotted mask ("255.255.255.0") or a prefix length
+// ("/24" or "24") and returns the prefix length and dotted form.
+func ParseMask(s string) (int, string, bool) {
+	s = strings.TrimSpace(s)
+
+	if !strings.Contains(s, ".") {
+		bits, err := strconv.Atoi(strings.TrimPrefix(s, "/"))
+		if err != nil || bits < 1 || bits > 32 {
+			return 0, "", false
+		}
+		return bits, MaskString(bits), true
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil || !addr.Is4() {
+		return 0, "", false
+	}
+
+	b := addr.As4()
+	n := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	bits := 0
+	for n&(1<<31) != 0 {
+		bits++
+		n <<= 1
+	}
+	if n != 0 || bits == 0 {
+		// ones must be contiguous from the left
+		return 0, "", false
+	}
+	return bits, addr.String(), true
+}
+
+// MaskString turns a prefix length into a dotted IPv4 mask.
+func MaskString(bits int) string {
+	n := ^uint32(0) << (32 - bits)
+	return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}).String()
+}
+
+func interfacePrefix(ip, mask string) (netip.Prefix, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Is4() {
+		return netip.Prefix{}, false
+	}
+	bits, _, ok := ParseMask(mask)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, bits).Masked(), true
+}
+
+func broadcast(p netip.Prefix) netip.Addr {
+	b := p.Addr().As4()
+	host := ^uint32(0) >> p.Bits()
+	n := (uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])) | host
+	return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+}
+
+// isDomainName checks RFC 1123 host name syntax.
+func isDomainName(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
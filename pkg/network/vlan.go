@@ -0,0 +1,47 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VLAN is a tagged interface on top of a hardware port.
+type VLAN struct {
+	Name   string `json:"name"`   // user defined name, the link name on Linux
+	Device string `json:"device"` // e.g. "vlan0" on macOS, same as Name on Linux
+	Parent string `json:"parent"` // device of the hardware port, e.g. "en0"
+	ID     int    `json:"id"`     // 802.1Q tag
+}
+
+// Validate checks a VLAN before it is created. existing is the current
+// interface tree, the parent must be a hardware port in it.
+func (v *VLAN) Validate(existing []HardwareInterface) FieldErrors {
+	var errs FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	v.Name = strings.TrimSpace(v.Name)
+	v.Parent = strings.TrimSpace(v.Parent)
+
+	if v.Name == "" {
+		add("name", "VLAN name is required")
+	}
+	if v.ID < 1 || v.ID > 4094 {
+		add("id", "%d is not a valid VLAN ID (1-4094)", v.ID)
+	}
+
+	parentFound := false
+	for _, hw := range existing {
+		if hw.Device == v.Parent && hw.Parent == "" {
+			parentFound = true
+		}
+		if hw.Parent == v.Parent && hw.VLANID == v.ID && v.ID != 0 {
+			add("id", "VLAN %d already exists on %s as %s", v.ID, v.Parent, hw.Device)
+		}
+	}
+	if !parentFound {
+		add("parent", "hardware port %q not found", v.Parent)
+	}
+	return errs
+}
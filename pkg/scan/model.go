@@ -0,0 +1,27 @@
+package scan
+
+// Request selects the subnet to sweep, either the one of a service or an
+// explicit CIDR.
+type Request struct {
+	Service    string `json:"service"` // LogicInterface name, wins over CIDR
+	CIDR       string `json:"cidr"`    // e.g. 192.168.144.0/24
+	Ports      []int  `json:"ports"`   // TCP connect probes, empty = none
+	ReverseDNS bool   `json:"reverseDns"`
+}
+
+type Host struct {
+	IP        string   `json:"ip"`
+	MAC       string   `json:"mac"`
+	Vendor    string   `json:"vendor"`
+	Hostname  string   `json:"hostname"` // reverse DNS
+	RTT       float64  `json:"rtt"`      // ms, 0 if ICMP got no answer
+	FoundBy   []string `json:"foundBy"`  // icmp/tcp/arp
+	OpenPorts []int    `json:"openPorts"`
+}
+
+type Progress struct {
+	Scanned int  `json:"scanned"`
+	Total   int  `json:"total"`
+	Found   int  `json:"found"`
+	Done    bool `json:"done"`
+}
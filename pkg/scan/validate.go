@@ -0,0 +1,69 @@
+package scan
+
+import (
+	"fmt"
+	"macbox/pkg/network"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// MaxHosts keeps a sweep to a /20, larger subnets take minutes and
+	// flood the ARP table.
+	MaxHosts = 4096
+	MaxPorts = 32
+)
+
+// Validate checks the ports and, when no service is given, the CIDR.
+func (r *Request) Validate() network.FieldErrors {
+	var errs network.FieldErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, network.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(r.Ports) > MaxPorts {
+		add("ports", "at most %d ports", MaxPorts)
+	}
+	for _, p := range r.Ports {
+		if p < 1 || p > 65535 {
+			add("ports", "%d is not a valid port", p)
+		}
+	}
+
+	if strings.TrimSpace(r.Service) != "" {
+		return errs
+	}
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(r.CIDR))
+	switch {
+	case err != nil || !prefix.Addr().Is4():
+		add("cidr", "%q is not an IPv4 subnet like 192.168.1.0/24", r.CIDR)
+	case HostCount(prefix) > MaxHosts:
+		add("cidr", "%s has more than %d addresses", prefix.Masked(), MaxHosts)
+	default:
+		r.CIDR = prefix.Masked().String()
+	}
+	return errs
+}
+
+// HostCount is the number of addresses Hosts returns for prefix.
+func HostCount(prefix netip.Prefix) int {
+	size := 1 << (32 - prefix.Bits())
+	if prefix.Bits() < 31 {
+		size -= 2 // network and broadcast
+	}
+	return size
+}
+
+// Hosts lists the addresses of prefix a sweep probes.
+func Hosts(prefix netip.Prefix) []netip.Addr {
+	prefix = prefix.Masked()
+	hosts := make([]netip.Addr, 0, HostCount(prefix))
+	addr := prefix.Addr()
+	if prefix.Bits() < 31 {
+		addr = addr.Next()
+	}
+	for ; prefix.Contains(addr) && len(hosts) < cap(hosts); addr = addr.Next() {
+		hosts = append(hosts, addr)
+	}
+	return hosts
+}
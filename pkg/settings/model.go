@@ -0,0 +1,35 @@
+package settings
+
+import (
+	"macbox/pkg/dhcp"
+	"macbox/pkg/mavlink"
+	"macbox/pkg/network"
+	"macbox/pkg/watcher"
+)
+
+// CurrentVersion is bumped whenever the file layout changes in a way that
+// needs a migration step.
+const CurrentVersion = 1
+
+type Settings struct {
+	Version int                   `json:"version"`
+	Watcher watcher.WatcherConfig `json:"watcher"`
+	History watcher.HistoryLimits `json:"history"`
+	Ping    PingSettings          `json:"ping"`
+	Update  UpdateSettings        `json:"update"`
+
+	DHCPServer       dhcp.ServerConfig       `json:"dhcpServer"`
+	MAVLinkDiscovery mavlink.DiscoveryConfig `json:"mavlinkDiscovery"`
+
+	Profiles []network.Profile `json:"profiles"`
+}
+
+type PingSettings struct {
+	Target string `json:"target"`
+	Count  int    `json:"count"` // 0 = infinite
+}
+
+type UpdateSettings struct {
+	CheckOnStartup bool   `json:"checkOnStartup"`
+	SkippedVersion string `json:"skippedVersion"`
+}
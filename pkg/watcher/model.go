@@ -16,14 +16,30 @@ type ParserMeta struct {
 }
 
 type WatcherConfig struct {
-	Protocol string `json:"protocol"` // udp/tcp
-	Port     int    `json:"port"`
-	Parser   string `json:"parser"`
+	Protocol   string `json:"protocol"` // udp/tcp/tcp-client
+	Port       int    `json:"port"`     // local port, remote port for tcp-client
+	Parser     string `json:"parser"`
+	RemoteHost string `json:"remoteHost"` // tcp-client only
+
+	BindAddress    string `json:"bindAddress"`    // empty = all interfaces
+	IPVersion      string `json:"ipVersion"`      // ""/ipv4/ipv6, empty = derived from addresses
+	MulticastGroup string `json:"multicastGroup"` // udp only, e.g. 239.255.145.50 or ff02::1
+	MulticastIface string `json:"multicastIface"` // device to join on, e.g. en0; empty = system default
+	ReusePort      bool   `json:"reusePort"`      // SO_REUSEADDR + SO_REUSEPORT
 }
 
 type WatcherState struct {
-	Config    WatcherConfig `json:"config"`
-	IsRunning bool          `json:"running"`
+	Config     WatcherConfig   `json:"config"`
+	IsRunning  bool            `json:"running"`
+	Connection ConnectionState `json:"connection"` // tcp-client only
+}
+
+type ConnectionState struct {
+	State   string `json:"state"` // connecting/connected/disconnected
+	Remote  string `json:"remote"`
+	Attempt int    `json:"attempt"`
+	RetryIn int64  `json:"retryIn"` // ms until the next attempt
+	Error   string `json:"error"`
 }
 
 type UDPPacket struct {
@@ -37,3 +53,47 @@ type UDPPacket struct {
 	FromIP     string         `json:"from_ip"`
 	Port       int            `json:"port"`
 }
+
+type HistoryLimits struct {
+	MaxPackets int `json:"maxPackets"` // 0 = unlimited
+	MaxBytes   int `json:"maxBytes"`   // 0 = unlimited
+}
+
+type HistoryStats struct {
+	Limits   HistoryLimits `json:"limits"`
+	Count    int           `json:"count"`
+	Bytes    int           `json:"bytes"`
+	OldestID int64         `json:"oldestId"`
+	NewestID int64         `json:"newestId"`
+	Dropped  int64         `json:"dropped"` // evicted by limits since last clear
+}
+
+type PacketQuery struct {
+	FromID int64     `json:"fromId"` // inclusive, 0 = from the oldest
+	ToID   int64     `json:"toId"`   // inclusive, 0 = up to the newest
+	Since  time.Time `json:"since"`  // zero = no lower bound
+	Until  time.Time `json:"until"`  // zero = no upper bound
+	Source string    `json:"source"` // "10.0.0.5", "10.0.0.5:14550" or "10.0.0.0/24"
+	Filter string    `json:"filter"` // see internal/filter
+	Offset int       `json:"offset"`
+	Limit  int       `json:"limit"`
+}
+
+type PacketPage struct {
+	Packets []UDPPacket `json:"packets"`
+	Total   int         `json:"total"` // matches before paging
+	Offset  int         `json:"offset"`
+	Error   string      `json:"error"`
+}
+
+type ExportRequest struct {
+	Format string      `json:"format"` // csv/ndjson/hexdump
+	Path   string      `json:"path"`   // empty = ask with a save dialog
+	Query  PacketQuery `json:"query"`  // paging fields are ignored
+}
+
+type ExportResult struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+	Error string `json:"error"`
+}
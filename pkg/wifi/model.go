@@ -0,0 +1,63 @@
+package wifi
+
+import "context"
+
+// Backend talks to the Wi-Fi hardware of one platform.
+type Backend interface {
+	// Interfaces lists the Wi-Fi devices, e.g. "en0" or "wlan0".
+	Interfaces() ([]string, error)
+	Scan(ctx context.Context, iface string) ([]Network, error)
+	Status(iface string) (Status, error)
+	Join(ctx context.Context, iface string, req JoinRequest) error
+	Leave(iface string) error
+}
+
+type Network struct {
+	SSID      string `json:"ssid"`
+	BSSID     string `json:"bssid"` // empty where the OS hides it
+	Channel   int    `json:"channel"`
+	Frequency int    `json:"frequency"` // MHz
+	RSSI      int    `json:"rssi"`      // dBm
+	Security  string `json:"security"`  // Open/WEP/WPA/WPA2 Personal/WPA2 Enterprise/WPA3
+}
+
+type ScanResult struct {
+	Networks []Network `json:"networks"` // strongest first
+	Error    string    `json:"error"`
+}
+
+type Status struct {
+	Interface string `json:"interface"`
+	PoweredOn bool   `json:"poweredOn"`
+	Connected bool   `json:"connected"`
+	SSID      string `json:"ssid"`
+	BSSID     string `json:"bssid"`
+	Channel   int    `json:"channel"`
+	RSSI      int    `json:"rssi"`     // dBm
+	Noise     int    `json:"noise"`    // dBm, 0 = unknown
+	TxRate    int    `json:"txRate"`   // Mbit/s
+	Security  string `json:"security"` // of the current network
+	Error     string `json:"error"`
+}
+
+type JoinRequest struct {
+	SSID     string `json:"ssid"`
+	Password string `json:"password"` // empty for open networks
+	Hidden   bool   `json:"hidden"`   // probe for the SSID, it is not broadcast
+}
+
+// Channel returns the channel number of a frequency in MHz, 0 if the
+// frequency is not in a Wi-Fi band.
+func Channel(freq int) int {
+	switch {
+	case freq == 2484:
+		return 14
+	case freq >= 2412 && freq <= 2472:
+		return (freq - 2407) / 5
+	case freq >= 5955 && freq <= 7115:
+		return (freq - 5950) / 5
+	case freq >= 5000 && freq <= 5900:
+		return (freq - 5000) / 5
+	}
+	return 0
+}
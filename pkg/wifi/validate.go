@@ -0,0 +1,24 @@
+package wifi
+
+import (
+	"macbox/pkg/network"
+	"strings"
+)
+
+// Validate checks the SSID and password lengths 802.11 allows.
+func (r *JoinRequest) Validate() network.FieldErrors {
+	var errs network.FieldErrors
+	if len(r.SSID) == 0 || len(r.SSID) > 32 {
+		errs = append(errs, network.FieldError{Field: "ssid", Message: "SSID must be 1 to 32 bytes"})
+	}
+
+	switch n := len(r.Password); {
+	case n == 0, n == 5, n == 13: // open, WEP-40, WEP-104
+	case n >= 8 && n <= 63:
+	case n == 64 && strings.Trim(strings.ToLower(r.Password), "0123456789abcdef") == "":
+		// raw 256 bit PSK
+	default:
+		errs = append(errs, network.FieldError{Field: "password", Message: "password must be 8 to 63 characters"})
+	}
+	return errs
+}